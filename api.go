@@ -0,0 +1,26 @@
+package routerosv7_restfull_api
+
+import "context"
+
+/*
+API is the set of requests of a RouterOS v7 REST API client, implemented by *Client.
+Code depending on API instead of *Client can be unit tested with the mock package instead of a router.
+example:
+func ListAddresses(ctx context.Context, api API) (interface{}, error) { return api.Print(ctx, "ip/address") }
+*/
+type API interface {
+	Auth(ctx context.Context) (interface{}, error)                                            // Check the credentials
+	Print(ctx context.Context, command string) (interface{}, error)                           // GET request
+	Add(ctx context.Context, command string, payload []byte) (interface{}, error)             // PUT request
+	Set(ctx context.Context, command string, payload []byte) (interface{}, error)             // PATCH request
+	Remove(ctx context.Context, command string) (interface{}, error)                          // DELETE request
+	Run(ctx context.Context, command string, payload []byte) (interface{}, error)             // POST request
+	AddPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) // PUT request of a value
+	SetPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) // PATCH request of a value
+	RunPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) // POST request of a value
+	RunQuery(ctx context.Context, command string, query *Query) (interface{}, error)          // POST request of a query
+	RunFilter(ctx context.Context, command, filter string) (interface{}, error)               // POST request of a filter
+}
+
+// Check if Client implements API
+var _ API = (*Client)(nil)
@@ -24,121 +24,115 @@ type APIRequest struct {
 	Method   string // Method for the request to Mikrotik Router
 }
 
-// URL constructs the request URL based on the method and path.
+/*
+URL constructs the request URL from the endpoint parsed from the host and the command, percent-encoding the command.
+A host that is not a valid endpoint is used as is.
+*/
 func (r *APIRequest) URL() string {
+	// Parse the host as an endpoint
+	endpoint, err := ParseEndpoint(r.Host)
+	if err == nil {
+		return endpoint.URL(r.Command) // Return the URL
+	}
+
 	protocol := determineProtocolFromURL(r.Host)                  // Determine the protocol from the URL
-	path := r.Command                                             // Set the path to the command
+	path := escapeCommand(r.Command)                              // Set the path to the escaped command
 	return fmt.Sprintf("%s://%s/rest/%s", protocol, r.Host, path) // Return the URL
 }
 
-// createAndExecuteRequest creates a request configuration and executes the request.
-func createAndExecuteRequest(ctx context.Context, request *APIRequest) (interface{}, error) {
-
-	// Create a request configuration
-	config := requestConfig{
-		URL:      request.URL(),    // Set the URL
-		Method:   request.Method,   // Set the method
-		Username: request.Username, // Set the username
-		Password: request.Password, // Set the password
-		Payload:  request.Payload,  // Set the payload
+// config creates a request configuration from the APIRequest.
+func (r *APIRequest) config() requestConfig {
+	return requestConfig{
+		URL:      r.URL(),    // Set the URL
+		Method:   r.Method,   // Set the method
+		Username: r.Username, // Set the username
+		Password: r.Password, // Set the password
+		Payload:  r.Payload,  // Set the payload
 	}
+}
 
-	// Execute the request and return the result and error
-	return makeRequest(ctx, config)
+/*
+newClient creates a Client for the host and credentials of a package-level function call.
+Every such client uses the shared package transport, so connections are reused between calls.
+*/
+func newClient(host, username, password string) (*Client, error) {
+	return NewClient(host, WithCredentials(username, password), WithTransport(packageTransport()))
 }
 
-// Auth function now uses createAndExecuteRequest with MethodGet
+// Auth creates a new Client from the AuthConfig and checks the credentials with it.
 func Auth(ctx context.Context, config AuthConfig) (interface{}, error) {
-	// Use the createAndExecuteRequest function with MethodGet
-	return createAndExecuteRequest(ctx, &APIRequest{
-		Host:     config.Host,       // Adjust the host as needed
-		Username: config.Username,   // Adjust the username as needed
-		Password: config.Password,   // Adjust the password as needed
-		Command:  "system/resource", // Adjust the command as needed
-		Method:   MethodGet,         // Use MethodGet
-		Payload:  nil,               // No payload needed
-	})
+
+	// Create a new Client
+	client, err := newClient(config.Host, config.Username, config.Password)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	// Return the result and error
+	return client.Auth(ctx)
 }
 
 // Print creates a new GET request and executes it, returning the result and error.
 func Print(ctx context.Context, host, username, password, command string) (interface{}, error) {
 
-	// Create a new APIRequest
-	request := &APIRequest{
-		Host:     host,      // Set the host
-		Username: username,  // Set the username
-		Password: password,  // Set the password
-		Command:  command,   // Set the command
-		Method:   MethodGet, // Set the method
+	// Create a new Client
+	client, err := newClient(host, username, password)
+	if err != nil {
+		return nil, err // Return nil and error
 	}
 
 	// Return the result and error
-	return createAndExecuteRequest(ctx, request)
+	return client.Print(ctx, command)
 }
 
 // Add creates a new PUT request and executes it, returning the result and error.
 func Add(ctx context.Context, host, username, password, command string, payload []byte) (interface{}, error) {
 
-	// Create a new APIRequest
-	request := &APIRequest{
-		Host:     host,      // Set the host
-		Username: username,  // Set the username
-		Password: password,  // Set the password
-		Command:  command,   // Set the command
-		Payload:  payload,   // Set the payload
-		Method:   MethodPut, // Set the method
+	// Create a new Client
+	client, err := newClient(host, username, password)
+	if err != nil {
+		return nil, err // Return nil and error
 	}
 
 	// Return the result and error
-	return createAndExecuteRequest(ctx, request)
+	return client.Add(ctx, command, payload)
 }
 
 // Set creates a new PATCH request and executes it, returning the result and error.
 func Set(ctx context.Context, host, username, password, command string, payload []byte) (interface{}, error) {
 
-	// Create a new APIRequest
-	request := &APIRequest{
-		Host:     host,        // Set the host
-		Username: username,    // Set the username
-		Password: password,    // Set the password
-		Command:  command,     // Set the command
-		Payload:  payload,     // Set the payload
-		Method:   MethodPatch, // Set the method
+	// Create a new Client
+	client, err := newClient(host, username, password)
+	if err != nil {
+		return nil, err // Return nil and error
 	}
 
 	// Return the result and error
-	return createAndExecuteRequest(ctx, request)
+	return client.Set(ctx, command, payload)
 }
 
 // Remove creates a new DELETE request and executes it, returning the result and error.
 func Remove(ctx context.Context, host, username, password, command string) (interface{}, error) {
 
-	// Create a new APIRequest
-	request := &APIRequest{
-		Host:     host,         // Set the host
-		Username: username,     // Set the username
-		Password: password,     // Set the password
-		Command:  command,      // Set the command
-		Method:   MethodDelete, // Set the method
+	// Create a new Client
+	client, err := newClient(host, username, password)
+	if err != nil {
+		return nil, err // Return nil and error
 	}
 
 	// Return the result and error
-	return createAndExecuteRequest(ctx, request)
+	return client.Remove(ctx, command)
 }
 
 // Run creates a new POST request and executes it, returning the result and error.
 func Run(ctx context.Context, host, username, password, command string, payload []byte) (interface{}, error) {
 
-	// Create a new APIRequest
-	request := &APIRequest{
-		Host:     host,       // Set the host
-		Username: username,   // Set the username
-		Password: password,   // Set the password
-		Command:  command,    // Set the command
-		Payload:  payload,    // Set the payload
-		Method:   MethodPost, // Set the method
+	// Create a new Client
+	client, err := newClient(host, username, password)
+	if err != nil {
+		return nil, err // Return nil and error
 	}
 
 	// Return the result and error
-	return createAndExecuteRequest(ctx, request)
+	return client.Run(ctx, command, payload)
 }
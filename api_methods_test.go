@@ -24,3 +24,18 @@ func TestURL(t *testing.T) {
 
 	assert.Equal(t, expectedURL, actualURL, "URL does not match expected")
 }
+
+func TestURL_EscapesCommand(t *testing.T) {
+
+	// Example:
+	request := &APIRequest{
+		Host:    "example.com",
+		Command: "ip/address/*1F?comment=a b#c",
+		Method:  MethodGet,
+	}
+
+	expectedURL := "http://example.com/rest/ip/address/*1F?comment=a+b%23c"
+	actualURL := request.URL()
+
+	assert.Equal(t, expectedURL, actualURL, "URL does not match expected")
+}
@@ -0,0 +1,23 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPI_Client tests that the requests of a client are executed through the API interface
+func TestAPI_Client(t *testing.T) {
+	client := newMockClient(t, http.StatusOK, `[{".id":"*1","address":"10.0.0.1/24"}]`)
+
+	var api API = client
+	result, err := api.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{".id": "*1", "address": "10.0.0.1/24"}}, result)
+
+	_, err = api.RunFilter(context.Background(), "ip/address/print", `address="10.0.0.1/24"`)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,228 @@
+/*
+Package cassette records the REST traffic of a client with a router to a cassette file and replays it, so that
+tests written against a lab router once can run in CI without it. A Recorder is an http.RoundTripper given to the
+client with WithTransport: in ModeRecord it sends the requests to the router and records the request/response
+pairs, with the credential headers and the secret fields of the request payloads scrubbed, and in ModeReplay it
+answers the requests from the cassette without network. Requests are matched on their method, path, query and
+normalized JSON payload, in the order they were recorded.
+example:
+
+	recorder, err := cassette.New("testdata/provision.json", cassette.ModeReplay)
+	client, err := routerosv7_restfull_api.NewClient("https://192.168.88.1",
+		routerosv7_restfull_api.WithTransport(recorder))
+*/
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Version is the format version of the cassette files written by this package
+const Version = 1
+
+// scrubbed replaces the values of the credential headers and of the secret payload fields in the cassettes
+const scrubbed = "REDACTED"
+
+// scrubbedHeaders are the headers whose values are never written to a cassette
+var scrubbedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+/*
+scrubbedFields are the RouterOS fields of the request payloads whose values are never written to a cassette,
+e.g. the password of "user/add" or the pre-shared key of a wireless security profile
+*/
+var scrubbedFields = map[string]bool{
+	"password":                true,
+	"passphrase":              true,
+	"secret":                  true,
+	"private-key":             true,
+	"preshared-key":           true,
+	"wpa-pre-shared-key":      true,
+	"wpa2-pre-shared-key":     true,
+	"authentication-password": true,
+	"encryption-password":     true,
+}
+
+// Cassette is the list of the recorded interactions, in the order they happened
+type Cassette struct {
+	Version      int           `json:"version"`      // Format version of the cassette
+	Interactions []Interaction `json:"interactions"` // Recorded request/response pairs
+}
+
+// Interaction is a recorded request and the response of the router
+type Interaction struct {
+	Request  Request  `json:"request"`  // Request sent to the router
+	Response Response `json:"response"` // Response of the router
+}
+
+// Request is a recorded request
+type Request struct {
+	Method string      `json:"method"`         // Method of the request, e.g. "GET"
+	URL    string      `json:"url"`            // URL of the request, without user information
+	Header http.Header `json:"header"`         // Header of the request, with the credentials scrubbed
+	Body   string      `json:"body,omitempty"` // Payload of the request, with the secret fields scrubbed
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`    // Status code of the response
+	Header     http.Header `json:"header"`         // Header of the response, with the cookies scrubbed
+	Body       string      `json:"body,omitempty"` // Body of the response, recorded as is
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("cassette: %s: %w", path, err)
+	}
+
+	// Check if the format is supported
+	if cassette.Version != Version {
+		return nil, fmt.Errorf("cassette: %s: unsupported version %d", path, cassette.Version)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette file, creating its directory if needed
+func (c *Cassette) Save(path string) error {
+	c.Version = Version
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+/*
+matches checks if the recorded request matches the request: same method, same path, same query parameters whatever
+their order, and same payload, compared as normalized JSON when both payloads are JSON
+*/
+func (r Request) matches(method string, u *url.URL, body []byte) bool {
+
+	// Check the method
+	if !strings.EqualFold(r.Method, method) {
+		return false
+	}
+
+	// Check the path and query
+	recorded, err := url.Parse(r.URL)
+	if err != nil || recorded.EscapedPath() != u.EscapedPath() || !sameQuery(recorded.Query(), u.Query()) {
+		return false
+	}
+
+	// Check the payload, scrubbed like the recorded payload
+	return normalizePayload([]byte(r.Body)) == normalizePayload([]byte(scrubBody(body)))
+}
+
+// sameQuery checks if the query parameters are equal, whatever their order
+func sameQuery(a, b url.Values) bool {
+	return a.Encode() == b.Encode()
+}
+
+// normalizePayload returns the payload with the whitespace and the order of the JSON object fields normalized
+func normalizePayload(payload []byte) string {
+	payload = bytes.TrimSpace(payload)
+
+	// Check if the payload is JSON
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return string(payload)
+	}
+
+	// Encode the payload again, which sorts the fields of the objects
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return string(payload)
+	}
+	return string(normalized)
+}
+
+// scrubHeader returns a copy of the header with the values of the credential headers replaced
+func scrubHeader(header http.Header) http.Header {
+	scrubbedHeader := header.Clone()
+	if scrubbedHeader == nil {
+		scrubbedHeader = http.Header{}
+	}
+	for _, name := range scrubbedHeaders {
+		if _, ok := scrubbedHeader[name]; ok {
+			scrubbedHeader[name] = []string{scrubbed}
+		}
+	}
+	return scrubbedHeader
+}
+
+/*
+scrubBody returns the JSON request payload with the values of the secret fields replaced at any depth. A payload
+that is not JSON or has no secret field is kept as is.
+*/
+func scrubBody(body []byte) string {
+
+	// Decode the payload, keeping the numbers as written
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || !scrubValue(value) {
+		return string(body)
+	}
+
+	// Encode the scrubbed payload
+	scrubbedBody, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+	return string(scrubbedBody)
+}
+
+// scrubValue replaces the values of the secret fields of the decoded JSON value, reporting if any was replaced
+func scrubValue(value interface{}) bool {
+	replaced := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			if scrubbedFields[strings.ToLower(name)] {
+				value[name] = scrubbed
+				replaced = true
+			} else if scrubValue(field) {
+				replaced = true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if scrubValue(item) {
+				replaced = true
+			}
+		}
+	}
+	return replaced
+}
+
+// scrubURL returns the URL without its user information
+func scrubURL(u *url.URL) string {
+	scrubbedURL := *u
+	scrubbedURL.User = nil
+	return scrubbedURL.String()
+}
+
+// describe returns the method, path and query of a request for the errors
+func describe(method string, u *url.URL) string {
+	if query := u.Query(); len(query) > 0 {
+		return method + " " + u.EscapedPath() + "?" + query.Encode()
+	}
+	return method + " " + u.EscapedPath()
+}
@@ -0,0 +1,222 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNoInteraction is returned in ModeReplay when no unused interaction of the cassette matches the request
+var ErrNoInteraction = errors.New("cassette: no matching interaction")
+
+// Mode is the mode of a Recorder
+type Mode int
+
+const (
+	ModeReplay Mode = iota // ModeReplay answers the requests from the cassette without network
+	ModeRecord             // ModeRecord sends the requests to the router and records them in the cassette
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "ModeReplay"
+	case ModeRecord:
+		return "ModeRecord"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Option configures a Recorder
+type Option func(*Recorder)
+
+/*
+WithTransport sets the transport sending the requests to the router in ModeRecord, http.DefaultTransport by default.
+The TLS options of the client are not applied to a transport given with WithTransport, so a lab router with a
+self-signed certificate needs a transport with its own TLS configuration.
+*/
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport // Set the transport
+	}
+}
+
+// WithScrubber adds a function scrubbing every interaction before it is recorded, e.g. to hide serial numbers
+func WithScrubber(scrubber func(*Interaction)) Option {
+	return func(r *Recorder) {
+		r.scrubbers = append(r.scrubbers, scrubber) // Add the scrubber
+	}
+}
+
+/*
+Recorder is an http.RoundTripper recording the interactions with a router to a cassette file or replaying them.
+It is safe for concurrent use, but concurrent requests are recorded in the order they complete, so requests whose
+order matters should be sent one after the other.
+*/
+type Recorder struct {
+	path      string               // Path of the cassette file
+	mode      Mode                 // Mode of the recorder
+	transport http.RoundTripper    // Transport sending the requests in ModeRecord
+	scrubbers []func(*Interaction) // Functions scrubbing the interactions before they are recorded
+
+	mu       sync.Mutex // Lock of the cassette
+	cassette *Cassette  // Recorded or loaded interactions
+	used     []bool     // Whether every interaction was replayed, in ModeReplay
+}
+
+/*
+New creates a Recorder of the cassette file in the mode. In ModeReplay the cassette is loaded and must exist;
+in ModeRecord a new cassette is written to the file by Stop.
+*/
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, transport: http.DefaultTransport, cassette: &Cassette{Version: Version}}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	switch mode {
+	case ModeRecord:
+		// Check if the transport is nil
+		if r.transport == nil {
+			return nil, errors.New("cassette: nil transport")
+		}
+	case ModeReplay:
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err // Return nil and error
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("cassette: unsupported mode %v", mode)
+	}
+	return r, nil
+}
+
+// Mode returns the mode of the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// RoundTrip records the request and the response of the router, or replays the response of the matching request
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	// Read the payload of the request, the body of the request is closed as required from a RoundTripper
+	var body []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		data, err := io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(request, body)
+	}
+	return r.record(request, body)
+}
+
+// record sends the request to the router and records the interaction
+func (r *Recorder) record(request *http.Request, body []byte) (*http.Response, error) {
+
+	// Send a copy of the request with the payload read
+	outgoing := request.Clone(request.Context())
+	if body != nil {
+		outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	response, err := r.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the body of the response, which is given back to the client
+	responseBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: request.Method,
+			URL:    scrubURL(request.URL),
+			Header: scrubHeader(request.Header),
+			Body:   scrubBody(body),
+		},
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Header:     scrubHeader(response.Header),
+			Body:       string(responseBody),
+		},
+	}
+	for _, scrub := range r.scrubbers {
+		scrub(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return response, nil
+}
+
+// replay answers the response of the first unused interaction matching the request
+func (r *Recorder) replay(request *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !interaction.Request.matches(request.Method, request.URL, body) {
+			continue
+		}
+		r.used[i] = true
+
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       request,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoInteraction, describe(request.Method, request.URL))
+}
+
+/*
+Unused returns the number of interactions of the cassette that were not replayed, so that a test can check that
+the code under test sent every recorded request. It is always zero in ModeRecord.
+*/
+func (r *Recorder) Unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unused := 0
+	for _, used := range r.used {
+		if !used {
+			unused++
+		}
+	}
+	return unused
+}
+
+// Stop writes the recorded cassette to its file in ModeRecord, it does nothing in ModeReplay
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
@@ -0,0 +1,178 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+	"github.com/sumitroajiprabowo/routerosv7-restfull-api/routerostest"
+)
+
+// session is the code under test, sending the same requests when recording and replaying
+func session(ctx context.Context, client *routeros.Client) ([]interface{}, error) {
+	var results []interface{}
+	steps := []func() (interface{}, error){
+		func() (interface{}, error) { return client.Print(ctx, "ip/address") },
+		func() (interface{}, error) {
+			return client.Add(ctx, "ip/address", []byte(`{"address":"10.0.0.1/24","interface":"ether1"}`))
+		},
+		func() (interface{}, error) { return client.Print(ctx, "ip/address") },
+		func() (interface{}, error) {
+			return client.RunQuery(ctx, "ip/address/print", routeros.NewQuery().Where("interface", "ether1"))
+		},
+	}
+	for _, step := range steps {
+		result, err := step()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// record records the session against a fake router with credentials and returns the results and the cassette path
+func record(t *testing.T, opts ...Option) ([]interface{}, string) {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	server := routerostest.NewServer(t, routerostest.WithCredentials("admin", "s3cr3t"),
+		routerostest.WithMenu("ip/address"))
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	recorder, err := New(path, ModeRecord, opts...)
+	require.NoError(t, err)
+	client, err := server.NewClient(routeros.WithTransport(recorder))
+	require.NoError(t, err)
+
+	results, err := session(context.Background(), client)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Stop())
+	return results, path
+}
+
+// TestRecorder_RecordReplay tests that a recorded session is replayed with the same results without the router
+func TestRecorder_RecordReplay(t *testing.T) {
+	recorded, path := record(t)
+
+	// The credentials are scrubbed from the cassette
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.NotContains(t, string(data), "YWRtaW46czNjcjN0")
+	assert.Contains(t, string(data), scrubbed)
+
+	cassette, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 4)
+
+	// Replay the session against a router that does not exist
+	recorder, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	router, err := url.Parse(cassette.Interactions[0].Request.URL)
+	require.NoError(t, err)
+	client, err := routeros.NewClient(router.Scheme+"://"+router.Host,
+		routeros.WithCredentials("admin", "other"), routeros.WithTransport(recorder))
+	require.NoError(t, err)
+
+	replayed, err := session(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+	assert.Zero(t, recorder.Unused())
+
+	// Every interaction is replayed once
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+// TestRecorder_ScrubsPayloads tests that the secret fields of the request payloads are never recorded
+func TestRecorder_ScrubsPayloads(t *testing.T) {
+	server := routerostest.NewServer(t, routerostest.WithMenu("user"))
+	path := filepath.Join(t.TempDir(), "user.json")
+	payload := []byte(`{"name":"ci","group":"full","password":"hunter2"}`)
+
+	recorder, err := New(path, ModeRecord)
+	require.NoError(t, err)
+	client, err := server.NewClient(routeros.WithTransport(recorder))
+	require.NoError(t, err)
+
+	// The router receives and answers the password
+	created, err := client.Add(context.Background(), "user", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", created.(map[string]interface{})["password"])
+	assert.Equal(t, "hunter2", server.Items("user")[0]["password"])
+	require.NoError(t, recorder.Stop())
+
+	// The cassette holds the password of the response as answered, but not the one of the request
+	cassette, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 1)
+	assert.JSONEq(t, `{"name":"ci","group":"full","password":"REDACTED"}`, cassette.Interactions[0].Request.Body)
+	assert.Contains(t, cassette.Interactions[0].Response.Body, `"password":"hunter2"`)
+
+	// The scrubbed request still matches the request with the password when replayed
+	replayer, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	client, err = routeros.NewClient(server.URL, routeros.WithTransport(replayer))
+	require.NoError(t, err)
+	_, err = client.Add(context.Background(), "user", payload)
+	require.NoError(t, err)
+	assert.Zero(t, replayer.Unused())
+}
+
+// TestRecorder_ReplayErrors tests the replay of an error response and of an unknown request
+func TestRecorder_ReplayErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.json")
+	cassette := &Cassette{Interactions: []Interaction{{
+		Request: Request{Method: http.MethodDelete, URL: "https://192.168.88.1/rest/ip/address/*9"},
+		Response: Response{StatusCode: http.StatusNotFound, Header: http.Header{"Content-Type": {"application/json"}},
+			Body: `{"error":404,"message":"Not Found"}`},
+	}}}
+	require.NoError(t, cassette.Save(path))
+
+	recorder, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	client, err := routeros.NewClient("https://192.168.88.1", routeros.WithTransport(recorder))
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/route")
+	assert.ErrorIs(t, err, ErrNoInteraction)
+	assert.ErrorContains(t, err, "GET /rest/ip/route")
+	assert.Equal(t, 1, recorder.Unused())
+
+	_, err = client.Remove(context.Background(), "ip/address/*9")
+	assert.True(t, routeros.IsNotFound(err))
+	assert.Zero(t, recorder.Unused())
+}
+
+// TestRecorder_WithScrubber tests that the scrubbers are applied before the interactions are recorded
+func TestRecorder_WithScrubber(t *testing.T) {
+	_, path := record(t, WithScrubber(func(interaction *Interaction) {
+		interaction.Response.Body = strings.ReplaceAll(interaction.Response.Body, "10.0.0.1", "192.0.2.1")
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "192.0.2.1")
+	assert.Contains(t, string(data), `10.0.0.1/24`) // The requests are kept, so that they still match
+}
+
+// TestNew_Errors tests the invalid recorders
+func TestNew_Errors(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = New("cassette.json", ModeRecord, WithTransport(nil))
+	assert.EqualError(t, err, "cassette: nil transport")
+
+	_, err = New("cassette.json", Mode(7))
+	assert.EqualError(t, err, "cassette: unsupported mode Mode(7)")
+	assert.Equal(t, "ModeRecord", ModeRecord.String())
+}
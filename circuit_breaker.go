@@ -0,0 +1,316 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned at once, without sending the request, while the circuit of a router is open
+var ErrCircuitOpen = errors.New("routeros: circuit open")
+
+// Defaults of the circuit breaker
+const (
+	DefaultFailureThreshold = 5                // DefaultFailureThreshold is the number of consecutive failures opening a circuit
+	DefaultCoolDown         = 30 * time.Second // DefaultCoolDown is the time an open circuit waits before a trial request
+)
+
+// CircuitState is the state of the circuit of a router
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // CircuitClosed lets every request through
+	CircuitOpen                         // CircuitOpen fails every request with ErrCircuitOpen until the cool-down ends
+	CircuitHalfOpen                     // CircuitHalfOpen lets trial requests through to check if the router is back
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitStateChange is the event emitted when the circuit of a router changes state
+type CircuitStateChange struct {
+	Endpoint string       // Endpoint of the router with its scheme, e.g. "https://192.168.88.1"
+	From     CircuitState // Previous state of the circuit
+	To       CircuitState // New state of the circuit
+	Err      error        // Failure that opened the circuit, nil otherwise
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker, zero fields take the defaults
+type CircuitBreakerConfig struct {
+	FailureThreshold int                      // Consecutive failures opening the circuit, DefaultFailureThreshold if zero
+	CoolDown         time.Duration            // Time an open circuit waits before a trial request, DefaultCoolDown if zero
+	HalfOpenRequests int                      // Successful trial requests closing the circuit, 1 if zero
+	IsFailure        func(err error) bool     // Classifier of the failures, DefaultCircuitFailure if nil
+	OnStateChange    func(CircuitStateChange) // Function called after every state change, may be nil
+}
+
+/*
+CircuitBreaker stops sending requests to an unreachable router, so that callers fail fast with ErrCircuitOpen
+instead of waiting for the connection timeout. Each router endpoint has its own circuit, which opens after
+FailureThreshold consecutive failures, lets trial requests through once the cool-down ends, and closes after
+HalfOpenRequests successful trials. A CircuitBreaker is safe for concurrent use and may be shared by the clients
+of a whole fleet with WithCircuitBreaker.
+*/
+type CircuitBreaker struct {
+	config   CircuitBreakerConfig // Configuration with the defaults applied
+	now      func() time.Time     // Clock, replaced in tests
+	mu       sync.Mutex           // Mutex protecting the circuits
+	circuits map[string]*circuit  // Circuits by endpoint
+}
+
+// circuit is the state of the circuit of a router
+type circuit struct {
+	state     CircuitState // Current state
+	failures  int          // Consecutive failures while closed
+	openedAt  time.Time    // Time at which the circuit opened
+	trials    int          // Trial requests in flight while half-open
+	successes int          // Successful trial requests while half-open
+}
+
+// NewCircuitBreaker creates a circuit breaker with the configuration
+func NewCircuitBreaker(config CircuitBreakerConfig) (*CircuitBreaker, error) {
+
+	// Check the configuration
+	if config.FailureThreshold < 0 || config.CoolDown < 0 || config.HalfOpenRequests < 0 {
+		return nil, errors.New("NewCircuitBreaker: thresholds and cool-down must not be negative")
+	}
+
+	// Apply the defaults
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = DefaultFailureThreshold
+	}
+	if config.CoolDown == 0 {
+		config.CoolDown = DefaultCoolDown
+	}
+	if config.HalfOpenRequests == 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultCircuitFailure
+	}
+
+	return &CircuitBreaker{config: config, now: time.Now, circuits: make(map[string]*circuit)}, nil
+}
+
+/*
+DefaultCircuitFailure reports whether the error shows that the router is unreachable or overloaded: a connection
+error other than a request cancelled by the caller, or a 502, 503 or 504 status code
+*/
+func DefaultCircuitFailure(err error) bool {
+
+	// Check if the caller cancelled the request
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Check if the router answered with an unavailable status code
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	// Check if the connection failed
+	var transportErr *TransportError
+	return errors.As(err, &transportErr)
+}
+
+// State returns the state of the circuit of the endpoint, e.g. "https://192.168.88.1"
+func (b *CircuitBreaker) State(endpoint string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		return CircuitClosed
+	}
+	return c.state
+}
+
+/*
+allow checks if a request to the endpoint may be sent, moving an open circuit to half-open once the cool-down
+ends. It reports whether the request is a trial request of a half-open circuit.
+*/
+func (b *CircuitBreaker) allow(endpoint string) (trial bool, err error) {
+	var change *CircuitStateChange
+	defer func() { b.notify(change) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		return false, nil // The circuit is closed
+	}
+
+	// Check if the cool-down of an open circuit ended
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.config.CoolDown)) {
+		change = &CircuitStateChange{Endpoint: endpoint, From: CircuitOpen, To: CircuitHalfOpen}
+		c.state, c.trials, c.successes = CircuitHalfOpen, 0, 0
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		return false, fmt.Errorf("%w: %s", ErrCircuitOpen, endpoint)
+	case CircuitHalfOpen:
+		// Check if enough trial requests are in flight
+		if c.trials+c.successes >= b.config.HalfOpenRequests {
+			return false, fmt.Errorf("%w: %s", ErrCircuitOpen, endpoint)
+		}
+		c.trials++
+		return true, nil
+	}
+	return false, nil
+}
+
+// outcome is the result of a request as seen by the circuit breaker
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota // outcomeSuccess is a request answered by the router
+	outcomeFailure                // outcomeFailure is a request classified as a failure by IsFailure
+	outcomeNeutral                // outcomeNeutral is a request cancelled by the caller before the router answered
+)
+
+/*
+outcome classifies the error of a request: a failure if IsFailure reports it, neutral if the caller cancelled the
+request, since it tells nothing about the router, and a success otherwise, e.g. a 404 answered by the router
+*/
+func (b *CircuitBreaker) outcome(err error) outcome {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case b.config.IsFailure(err):
+		return outcomeFailure
+	case errors.Is(err, context.Canceled):
+		return outcomeNeutral
+	}
+	return outcomeSuccess
+}
+
+// record records the result of a request to the endpoint, opening or closing its circuit
+func (b *CircuitBreaker) record(endpoint string, trial bool, err error) {
+	var change *CircuitStateChange
+	defer func() { b.notify(change) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+	result := b.outcome(err)
+
+	// Check if the request was a trial request of a half-open circuit
+	if trial {
+		if c.state != CircuitHalfOpen {
+			return // The circuit changed state meanwhile
+		}
+		c.trials--
+
+		// A neutral trial only releases its slot
+		if result == outcomeNeutral {
+			return
+		}
+
+		// A failed trial opens the circuit again
+		if result == outcomeFailure {
+			change = &CircuitStateChange{Endpoint: endpoint, From: CircuitHalfOpen, To: CircuitOpen, Err: err}
+			c.state, c.openedAt = CircuitOpen, b.now()
+			return
+		}
+
+		// Enough successful trials close the circuit
+		c.successes++
+		if c.successes >= b.config.HalfOpenRequests {
+			change = &CircuitStateChange{Endpoint: endpoint, From: CircuitHalfOpen, To: CircuitClosed}
+			c.state, c.failures = CircuitClosed, 0
+		}
+		return
+	}
+
+	// Check if the circuit opened meanwhile
+	if c.state != CircuitClosed {
+		return
+	}
+
+	// A neutral request leaves the consecutive failures unchanged
+	if result == outcomeNeutral {
+		return
+	}
+
+	// A success resets the consecutive failures
+	if result == outcomeSuccess {
+		c.failures = 0
+		return
+	}
+
+	// Enough consecutive failures open the circuit
+	c.failures++
+	if c.failures >= b.config.FailureThreshold {
+		change = &CircuitStateChange{Endpoint: endpoint, From: CircuitClosed, To: CircuitOpen, Err: err}
+		c.state, c.openedAt, c.failures = CircuitOpen, b.now(), 0
+	}
+}
+
+// notify calls the state change function with the change, if any
+func (b *CircuitBreaker) notify(change *CircuitStateChange) {
+	if change != nil && b.config.OnStateChange != nil {
+		b.config.OnStateChange(*change)
+	}
+}
+
+// circuitBreakerMiddleware fails the requests at once while the circuit of their endpoint is open
+func circuitBreakerMiddleware(breaker *CircuitBreaker) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			endpoint := request.Host
+
+			// Check if the circuit lets the request through
+			trial, err := breaker.allow(endpoint)
+			if err != nil {
+				return nil, err // Return nil and error
+			}
+
+			response, err := next.Do(ctx, request)
+			breaker.record(endpoint, trial, err)
+			return response, err
+		})
+	}
+}
+
+/*
+WithCircuitBreaker fails the requests of the client with ErrCircuitOpen while the circuit of its router is open.
+The circuit breaker sees a request once, after its retries.
+example:
+breaker, _ := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, CoolDown: time.Minute})
+NewClient("192.168.88.1", WithCircuitBreaker(breaker))
+*/
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *clientOptions) error {
+		// Check if the circuit breaker is nil
+		if breaker == nil {
+			return errors.New("WithCircuitBreaker: nil circuit breaker")
+		}
+		o.circuitBreaker = breaker // Set the circuit breaker
+		return nil
+	}
+}
@@ -0,0 +1,204 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errUnreachable is a connection error for the state machine tests
+var errUnreachable = &TransportError{Err: errors.New("connection refused")}
+
+// newTestCircuitBreaker creates a circuit breaker with a fake clock, recording its state changes
+func newTestCircuitBreaker(t *testing.T, config CircuitBreakerConfig) (*CircuitBreaker, *time.Time,
+	*[]CircuitStateChange) {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	var changes []CircuitStateChange
+	config.OnStateChange = func(change CircuitStateChange) { changes = append(changes, change) }
+
+	breaker, err := NewCircuitBreaker(config)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now, &changes
+}
+
+// TestCircuitBreaker_StateMachine tests the transitions between the closed, open and half-open states
+func TestCircuitBreaker_StateMachine(t *testing.T) {
+	const endpoint = "https://192.168.88.1"
+	breaker, now, changes := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 2, CoolDown: time.Minute})
+
+	// A success resets the consecutive failures
+	breaker.record(endpoint, false, errUnreachable)
+	breaker.record(endpoint, false, nil)
+	breaker.record(endpoint, false, errUnreachable)
+	assert.Equal(t, CircuitClosed, breaker.State(endpoint))
+
+	// The second consecutive failure opens the circuit
+	breaker.record(endpoint, false, errUnreachable)
+	assert.Equal(t, CircuitOpen, breaker.State(endpoint))
+	_, err := breaker.allow(endpoint)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// The cool-down lets a single trial through
+	*now = now.Add(time.Minute)
+	trial, err := breaker.allow(endpoint)
+	require.NoError(t, err)
+	assert.True(t, trial)
+	assert.Equal(t, CircuitHalfOpen, breaker.State(endpoint))
+	_, err = breaker.allow(endpoint)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// A failed trial opens the circuit again
+	breaker.record(endpoint, true, errUnreachable)
+	assert.Equal(t, CircuitOpen, breaker.State(endpoint))
+
+	// A successful trial closes the circuit
+	*now = now.Add(time.Minute)
+	trial, err = breaker.allow(endpoint)
+	require.NoError(t, err)
+	breaker.record(endpoint, trial, nil)
+	assert.Equal(t, CircuitClosed, breaker.State(endpoint))
+
+	assert.Equal(t, []CircuitStateChange{
+		{Endpoint: endpoint, From: CircuitClosed, To: CircuitOpen, Err: errUnreachable},
+		{Endpoint: endpoint, From: CircuitOpen, To: CircuitHalfOpen},
+		{Endpoint: endpoint, From: CircuitHalfOpen, To: CircuitOpen, Err: errUnreachable},
+		{Endpoint: endpoint, From: CircuitOpen, To: CircuitHalfOpen},
+		{Endpoint: endpoint, From: CircuitHalfOpen, To: CircuitClosed},
+	}, *changes)
+}
+
+// TestCircuitBreaker_HalfOpenRequests tests that several successful trials are needed to close the circuit
+func TestCircuitBreaker_HalfOpenRequests(t *testing.T) {
+	const endpoint = "http://router.lan"
+	breaker, now, _ := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 1, HalfOpenRequests: 2})
+
+	breaker.record(endpoint, false, errUnreachable)
+	*now = now.Add(DefaultCoolDown)
+
+	for i := 0; i < 2; i++ {
+		trial, err := breaker.allow(endpoint)
+		require.NoError(t, err)
+		assert.True(t, trial)
+	}
+	_, err := breaker.allow(endpoint)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	breaker.record(endpoint, true, nil)
+	assert.Equal(t, CircuitHalfOpen, breaker.State(endpoint))
+	breaker.record(endpoint, true, nil)
+	assert.Equal(t, CircuitClosed, breaker.State(endpoint))
+}
+
+// TestCircuitBreaker_Cancelled tests that requests cancelled by the caller leave the circuit unchanged
+func TestCircuitBreaker_Cancelled(t *testing.T) {
+	const endpoint = "https://192.168.88.1"
+	breaker, now, changes := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 2})
+	cancelled := &TransportError{Err: context.Canceled}
+
+	// A cancelled request in the closed state does not reset the consecutive failures
+	breaker.record(endpoint, false, errUnreachable)
+	breaker.record(endpoint, false, cancelled)
+	assert.Equal(t, CircuitClosed, breaker.State(endpoint))
+	breaker.record(endpoint, false, errUnreachable)
+	assert.Equal(t, CircuitOpen, breaker.State(endpoint))
+
+	// A cancelled trial does not close the circuit and releases its slot
+	*now = now.Add(DefaultCoolDown)
+	trial, err := breaker.allow(endpoint)
+	require.NoError(t, err)
+	breaker.record(endpoint, trial, cancelled)
+	assert.Equal(t, CircuitHalfOpen, breaker.State(endpoint))
+
+	trial, err = breaker.allow(endpoint)
+	require.NoError(t, err)
+	assert.True(t, trial)
+	breaker.record(endpoint, trial, errUnreachable)
+	assert.Equal(t, CircuitOpen, breaker.State(endpoint))
+
+	assert.Equal(t, []CircuitStateChange{
+		{Endpoint: endpoint, From: CircuitClosed, To: CircuitOpen, Err: errUnreachable},
+		{Endpoint: endpoint, From: CircuitOpen, To: CircuitHalfOpen},
+		{Endpoint: endpoint, From: CircuitHalfOpen, To: CircuitOpen, Err: errUnreachable},
+	}, *changes)
+}
+
+// TestWithCircuitBreaker_UnreachableRouter tests that requests fail fast once the circuit of a router is open
+func TestWithCircuitBreaker_UnreachableRouter(t *testing.T) {
+	breaker, _, changes := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 2})
+
+	client, err := NewClient(closedLocalAddress(t), WithCircuitBreaker(breaker))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Print(context.Background(), "ip/address")
+		var transportErr *TransportError
+		assert.ErrorAs(t, err, &transportErr)
+	}
+
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = PrintAs[[]map[string]string](context.Background(), client, "ip/address")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	require.Len(t, *changes, 1)
+	assert.Equal(t, "http://"+client.Endpoint().HostPort(), (*changes)[0].Endpoint)
+	assert.Equal(t, CircuitOpen, (*changes)[0].To)
+}
+
+// TestWithCircuitBreaker_Endpoints tests that the routers sharing a circuit breaker have their own circuits
+func TestWithCircuitBreaker_Endpoints(t *testing.T) {
+	breaker, _, _ := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 1})
+	server, requests := setupRecordingServer(t, `[]`)
+
+	down, err := NewClient(closedLocalAddress(t), WithCircuitBreaker(breaker))
+	require.NoError(t, err)
+	up, err := NewClient(serverHost(server), WithCircuitBreaker(breaker))
+	require.NoError(t, err)
+
+	_, err = down.Print(context.Background(), "ip/address")
+	assert.Error(t, err)
+	_, err = down.Print(context.Background(), "ip/address")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	_, err = up.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+	assert.Len(t, *requests, 1)
+}
+
+// TestWithCircuitBreaker_NotFailures tests that API errors and cancelled requests do not open the circuit
+func TestWithCircuitBreaker_NotFailures(t *testing.T) {
+	breaker, _, _ := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 1})
+
+	client := newMockClient(t, http.StatusNotFound, `{"error":404}`, WithCircuitBreaker(breaker))
+	_, err := client.Print(context.Background(), "ip/address/*9")
+	assert.True(t, IsNotFound(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Print(ctx, "ip/address")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, CircuitClosed, breaker.State("http://"+client.Endpoint().HostPort()))
+}
+
+// TestCircuitBreaker_Invalid tests that invalid configurations and a nil circuit breaker are rejected
+func TestCircuitBreaker_Invalid(t *testing.T) {
+	_, err := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: -1})
+	assert.Error(t, err)
+	_, err = NewCircuitBreaker(CircuitBreakerConfig{CoolDown: -time.Second})
+	assert.Error(t, err)
+	_, err = NewClient("router.lan", WithCircuitBreaker(nil))
+	assert.EqualError(t, err, "WithCircuitBreaker: nil circuit breaker")
+
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	assert.Equal(t, "CircuitState(7)", CircuitState(7).String())
+}
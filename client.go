@@ -0,0 +1,332 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+/*
+Client is a reusable client for the RouterOS v7 REST API of a single Mikrotik Router.
+It is created once with NewClient and shares one HTTP client, and therefore one connection pool,
+between every request. A Client is safe for concurrent use by multiple goroutines.
+*/
+type Client struct {
+	host        string               // Host of the Mikrotik Router as given to NewClient
+	endpoint    Endpoint             // Endpoint parsed from the host
+	username    string               // Username for the request to Mikrotik Router
+	password    string               // Password for the request to Mikrotik Router
+	userAgent   string               // User-Agent header sent with every request
+	policy      ProtocolPolicy       // Protocol policy of the client
+	onDowngrade func(DowngradeEvent) // Function called before a request is downgraded to HTTP
+	httpClient  *http.Client         // HTTP client shared by every request
+	doer        Doer                 // Doer executing the requests through the middlewares
+	limiter     *Limiter             // Limiter of the requests sent to the router, nil for no limit
+	logger      *slog.Logger         // Logger of the client, discarding the logs by default
+
+	protocolCache *ProtocolCache // Cache of the protocol detected by ProtocolAutoDetect
+	httpsPort     string         // HTTPS port probed by ProtocolAutoDetect
+	httpPort      string         // HTTP port probed by ProtocolAutoDetect
+	probeTimeout  time.Duration  // Deadline for probing the ports of the router
+}
+
+/*
+NewClient creates a new Client for the given host configured with the provided options.
+The host is parsed with ParseEndpoint and may be a hostname or an IP address with an optional port and scheme.
+It returns an error if the host is empty or invalid, or if any of the options is invalid.
+example:
+NewClient("192.168.88.1", WithCredentials("admin", "password"), WithTimeout(10*time.Second))
+NewClient("https://[fe80::1%eth0]:8443", WithCredentials("admin", "password"))
+*/
+func NewClient(host string, opts ...Option) (*Client, error) {
+
+	// Check if the host is empty
+	if host == "" {
+		return nil, errors.New("NewClient: empty host")
+	}
+
+	// Parse the host as an endpoint
+	endpoint, err := ParseEndpoint(host)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	// Apply the options
+	var options clientOptions
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err // Return nil and error
+		}
+	}
+
+	// Override the scheme of the endpoint if requested
+	if options.scheme != "" {
+		endpoint = endpoint.WithScheme(options.scheme)
+	}
+
+	// Apply the protocol policy to the endpoint
+	endpoint, err = applyProtocolPolicy(endpoint, options.protocolPolicy)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	// Set the defaults of ProtocolAutoDetect
+	if options.protocolCache == nil {
+		options.protocolCache = defaultProtocolCache
+	}
+	if options.httpsPort == "" {
+		options.httpsPort, options.httpPort = "443", "80"
+	}
+	if options.probeTimeout == 0 {
+		options.probeTimeout = DefaultProbeTimeout
+	}
+
+	// Discard the logs unless a logger is provided
+	options.logger = loggerOrDiscard(options.logger)
+
+	// Create the limiter of the requests
+	limiter, err := newClientLimiter(options)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	// Create the HTTP client shared by every request
+	httpClient := &http.Client{
+		Transport: newTransport(options, endpoint),
+		Timeout:   options.timeout,
+	}
+
+	// Create the client
+	client := &Client{
+		host:        host,
+		endpoint:    endpoint,
+		username:    options.username,
+		password:    options.password,
+		userAgent:   options.userAgent,
+		policy:      options.protocolPolicy,
+		onDowngrade: options.downgradeHook,
+		httpClient:  httpClient,
+		limiter:     limiter,
+		logger:      options.logger,
+
+		protocolCache: options.protocolCache,
+		httpsPort:     options.httpsPort,
+		httpPort:      options.httpPort,
+		probeTimeout:  options.probeTimeout,
+	}
+
+	// Wrap the sending of the requests with the limiter, the retries, the circuit breaker and the middlewares
+	var doer Doer = DoerFunc(client.send)
+	if limiter != nil {
+		doer = limiterMiddleware(limiter)(doer)
+	}
+	if options.retryPolicy != nil {
+		doer = retryMiddleware(*options.retryPolicy)(doer)
+	}
+	if options.circuitBreaker != nil {
+		doer = circuitBreakerMiddleware(options.circuitBreaker)(doer)
+	}
+	client.doer = chainMiddlewares(doer, options.middlewares)
+
+	// Return the client
+	return client, nil
+}
+
+/*
+newTransport returns the transport configured in the options, or a new transport cloned from
+http.DefaultTransport with the TLS configuration from the options for the endpoint otherwise
+*/
+func newTransport(options clientOptions, endpoint Endpoint) http.RoundTripper {
+
+	// Use the transport provided by the caller if any
+	if options.transport != nil {
+		return options.transport
+	}
+
+	// Clone the default transport to get sane pooling and proxy defaults
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// Set the TLS configuration
+	transport.TLSClientConfig = newTLSConfig(options, endpoint)
+
+	// Apply the connection pooling settings
+	applyTransportOptions(transport, options.pool)
+
+	// Return the transport
+	return transport
+}
+
+// Host returns the host of the Mikrotik Router the client talks to
+func (c *Client) Host() string {
+	return c.host
+}
+
+// Endpoint returns the endpoint of the Mikrotik Router the client talks to
+func (c *Client) Endpoint() Endpoint {
+	return c.endpoint
+}
+
+/*
+resolveEndpoint returns the endpoint of the router. With ProtocolAutoDetect, the protocol is read from the cache,
+or detected by probing the ports of the router and cached otherwise.
+*/
+func (c *Client) resolveEndpoint(ctx context.Context) (Endpoint, error) {
+
+	// Check if the protocol is detected
+	if c.policy != ProtocolAutoDetect {
+		return c.endpoint, nil
+	}
+
+	// Read the protocol from the cache, or probe the ports of the router
+	protocol, ok := c.protocolCache.Get(c.endpoint.Host, c.httpsPort, c.httpPort)
+	if !ok {
+		probeCtx, cancel := context.WithTimeout(ctx, c.probeTimeout)
+		defer cancel()
+
+		var err error
+		protocol, err = determineProtocol(probeCtx, c.logger, c.endpoint.Host, c.httpsPort, c.httpPort)
+		if err != nil {
+			return Endpoint{}, &TransportError{URL: c.endpoint.Host, Err: err}
+		}
+		c.protocolCache.Set(c.endpoint.Host, c.httpsPort, c.httpPort, protocol)
+	}
+
+	// Use the probed port unless it is the default port of the protocol
+	endpoint := c.endpoint.WithScheme(protocol)
+	switch {
+	case protocol == httpsProtocol && c.httpsPort != "443":
+		endpoint.Port = c.httpsPort
+	case protocol == httpProtocol && c.httpPort != "80":
+		endpoint.Port = c.httpPort
+	}
+	return endpoint, nil
+}
+
+/*
+Protocol returns the protocol, "http" or "https", used for the next request.
+With ProtocolAutoDetect, the protocol is detected if it is not cached.
+*/
+func (c *Client) Protocol(ctx context.Context) (string, error) {
+	endpoint, err := c.resolveEndpoint(ctx)
+	if err != nil {
+		return "", err
+	}
+	return endpoint.EffectiveScheme(), nil
+}
+
+// invalidateProtocol removes the detected protocol from the cache after a connection error
+func (c *Client) invalidateProtocol(err error) {
+	var transportErr *TransportError
+	if c.policy == ProtocolAutoDetect && errors.As(err, &transportErr) {
+		c.protocolCache.Invalidate(c.endpoint.Host, c.httpsPort, c.httpPort)
+	}
+}
+
+// requestConfig creates a request configuration for the request with the client's credentials
+func (c *Client) requestConfig(request *Request) requestConfig {
+
+	// Create a new APIRequest
+	apiRequest := &APIRequest{
+		Host:     request.Host,    // Set the host with its scheme
+		Username: c.username,      // Set the username
+		Password: c.password,      // Set the password
+		Command:  request.Command, // Set the command
+		Payload:  request.Payload, // Set the payload
+		Method:   request.Method,  // Set the method
+	}
+
+	// Create a request configuration
+	config := apiRequest.config()
+	config.UserAgent = c.userAgent
+	config.Header = request.Header
+	config.AllowDowngrade = c.policy == PreferHTTPSWithFallback
+	config.OnDowngrade = c.onDowngrade
+	config.Logger = c.logger
+
+	// Return the request configuration
+	return config
+}
+
+// send is the innermost Doer of the client, it sends the request with the shared HTTP client
+func (c *Client) send(ctx context.Context, request *Request) (*Response, error) {
+	statusCode, body, err := fetchResponse(ctx, c.httpClient, c.requestConfig(request))
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+	return &Response{StatusCode: statusCode, Body: body}, nil
+}
+
+/*
+fetch resolves the endpoint and executes the request through the middlewares, returning the raw response body
+and the request configuration
+*/
+func (c *Client) fetch(ctx context.Context, method, command string, payload []byte) ([]byte, requestConfig, error) {
+
+	// Resolve the endpoint
+	endpoint, err := c.resolveEndpoint(ctx)
+	if err != nil {
+		return nil, requestConfig{}, err
+	}
+
+	// Create the request
+	request := &Request{
+		Method:  method,
+		Host:    endpoint.String(),
+		Command: command,
+		Payload: payload,
+		Header:  http.Header{},
+	}
+
+	// Execute the request through the middlewares
+	response, err := c.doer.Do(ctx, request)
+	c.invalidateProtocol(err)
+	if err != nil {
+		return nil, requestConfig{}, err
+	}
+	return response.Body, c.requestConfig(request), nil
+}
+
+// execute executes the request through the middlewares and decodes the JSON response body
+func (c *Client) execute(ctx context.Context, method, command string, payload []byte) (interface{}, error) {
+
+	// Fetch the raw response body
+	body, config, err := c.fetch(ctx, method, command, payload)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	// Decode the response body
+	return decodeResponseBody(config, body)
+}
+
+// Auth checks the credentials by reading system/resource, returning the result and error.
+func (c *Client) Auth(ctx context.Context) (interface{}, error) {
+	return c.execute(ctx, MethodGet, "system/resource", nil)
+}
+
+// Print creates a new GET request and executes it, returning the result and error.
+func (c *Client) Print(ctx context.Context, command string) (interface{}, error) {
+	return c.execute(ctx, MethodGet, command, nil)
+}
+
+// Add creates a new PUT request and executes it, returning the result and error.
+func (c *Client) Add(ctx context.Context, command string, payload []byte) (interface{}, error) {
+	return c.execute(ctx, MethodPut, command, payload)
+}
+
+// Set creates a new PATCH request and executes it, returning the result and error.
+func (c *Client) Set(ctx context.Context, command string, payload []byte) (interface{}, error) {
+	return c.execute(ctx, MethodPatch, command, payload)
+}
+
+// Remove creates a new DELETE request and executes it, returning the result and error.
+func (c *Client) Remove(ctx context.Context, command string) (interface{}, error) {
+	return c.execute(ctx, MethodDelete, command, nil)
+}
+
+// Run creates a new POST request and executes it, returning the result and error.
+func (c *Client) Run(ctx context.Context, command string, payload []byte) (interface{}, error) {
+	return c.execute(ctx, MethodPost, command, payload)
+}
@@ -0,0 +1,217 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest holds the parts of a request received by the recording server
+type recordedRequest struct {
+	Method    string // Method of the request
+	Path      string // Path of the request
+	Body      string // Body of the request
+	Username  string // BasicAuth username of the request
+	Password  string // BasicAuth password of the request
+	UserAgent string // User-Agent header of the request
+}
+
+// setupRecordingServer sets up a mock server that records every request and answers with the response body
+func setupRecordingServer(t *testing.T, responseBody string) (*httptest.Server, *[]recordedRequest) {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		username, password, _ := r.BasicAuth()
+		requests = append(requests, recordedRequest{
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Body:      string(body),
+			Username:  username,
+			Password:  password,
+			UserAgent: r.UserAgent(),
+		})
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// serverHost returns the host and port of the mock server without the scheme
+func serverHost(server *httptest.Server) string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// TestNewClient_EmptyHost tests that NewClient rejects an empty host
+func TestNewClient_EmptyHost(t *testing.T) {
+	_, err := NewClient("")
+	assert.Error(t, err)
+}
+
+// TestNewClient_InvalidOptions tests that NewClient returns the error of an invalid option
+func TestNewClient_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name   string // Test case name
+		option Option // Option under test
+	}{
+		{"Negative timeout", WithTimeout(-time.Second)},
+		{"Nil TLS config", WithTLSConfig(nil)},
+		{"Nil transport", WithTransport(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient("example.com", tt.option)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestNewClient_Options tests that NewClient applies the options to the client
+func TestNewClient_Options(t *testing.T) {
+	tlsConfig := &tls.Config{ServerName: "router"}
+
+	client, err := NewClient("example.com",
+		WithCredentials("user", "pass"),
+		WithTimeout(5*time.Second),
+		WithTLSConfig(tlsConfig),
+		WithUserAgent("agent/1.0"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", client.Host())
+	assert.Equal(t, "user", client.username)
+	assert.Equal(t, "pass", client.password)
+	assert.Equal(t, "agent/1.0", client.userAgent)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+
+	// The TLS configuration is cloned, not shared with the caller
+	transport := client.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, "router", transport.TLSClientConfig.ServerName)
+	assert.NotSame(t, tlsConfig, transport.TLSClientConfig)
+}
+
+// TestNewClient_WithTransport tests that a transport provided by the caller is used as is
+func TestNewClient_WithTransport(t *testing.T) {
+	transport := &http.Transport{}
+
+	client, err := NewClient("example.com", WithTransport(transport))
+	require.NoError(t, err)
+
+	assert.Same(t, transport, client.httpClient.Transport)
+}
+
+// TestClient_Verbs tests that every verb sends the expected method, path, payload and credentials
+func TestClient_Verbs(t *testing.T) {
+	server, requests := setupRecordingServer(t, `{"ret": "*1"}`)
+
+	client, err := NewClient(serverHost(server), WithCredentials("user", "pass"), WithUserAgent("agent/1.0"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	payload := []byte(`{"address": "192.168.99.1/24"}`)
+
+	tests := []struct {
+		name   string                      // Test case name
+		call   func() (interface{}, error) // Call under test
+		expect recordedRequest             // Expected request received by the server
+	}{
+		{name: "Auth", call: func() (interface{}, error) { return client.Auth(ctx) },
+			expect: recordedRequest{Method: MethodGet, Path: "/rest/system/resource"}},
+		{name: "Print", call: func() (interface{}, error) { return client.Print(ctx, "ip/address") },
+			expect: recordedRequest{Method: MethodGet, Path: "/rest/ip/address"}},
+		{name: "Add", call: func() (interface{}, error) { return client.Add(ctx, "ip/address", payload) },
+			expect: recordedRequest{Method: MethodPut, Path: "/rest/ip/address", Body: string(payload)}},
+		{name: "Set", call: func() (interface{}, error) { return client.Set(ctx, "ip/address/*1", payload) },
+			expect: recordedRequest{Method: MethodPatch, Path: "/rest/ip/address/*1", Body: string(payload)}},
+		{name: "Remove", call: func() (interface{}, error) { return client.Remove(ctx, "ip/address/*1") },
+			expect: recordedRequest{Method: MethodDelete, Path: "/rest/ip/address/*1"}},
+		{name: "Run", call: func() (interface{}, error) { return client.Run(ctx, "ip/address/print", payload) },
+			expect: recordedRequest{Method: MethodPost, Path: "/rest/ip/address/print", Body: string(payload)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*requests = nil
+
+			result, err := tt.call()
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"ret": "*1"}, result)
+
+			require.Len(t, *requests, 1)
+			tt.expect.Username = "user"
+			tt.expect.Password = "pass"
+			tt.expect.UserAgent = "agent/1.0"
+			assert.Equal(t, tt.expect, (*requests)[0])
+		})
+	}
+}
+
+// TestClient_SharesConnections tests that consecutive requests reuse the same connection
+func TestClient_SharesConnections(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Print(context.Background(), "interface")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
+
+// TestPackageFunctions tests that the package-level functions keep working as wrappers around Client
+func TestPackageFunctions(t *testing.T) {
+	server, requests := setupRecordingServer(t, `{}`)
+	host := serverHost(server)
+	ctx := context.Background()
+
+	_, err := Auth(ctx, AuthConfig{Host: host, Username: "user", Password: "pass"})
+	assert.NoError(t, err)
+	_, err = Print(ctx, host, "user", "pass", "ip/address")
+	assert.NoError(t, err)
+	_, err = Add(ctx, host, "user", "pass", "ip/address", []byte(`{}`))
+	assert.NoError(t, err)
+	_, err = Set(ctx, host, "user", "pass", "ip/address/*1", []byte(`{}`))
+	assert.NoError(t, err)
+	_, err = Remove(ctx, host, "user", "pass", "ip/address/*1")
+	assert.NoError(t, err)
+	_, err = Run(ctx, host, "user", "pass", "ip/address/print", []byte(`{}`))
+	assert.NoError(t, err)
+
+	// Check the methods received by the server
+	var methods []string
+	for _, request := range *requests {
+		methods = append(methods, request.Method)
+		assert.Equal(t, "user", request.Username)
+	}
+	assert.Equal(t, []string{MethodGet, MethodGet, MethodPut, MethodPatch, MethodDelete, MethodPost}, methods)
+
+	// An empty host is rejected before any request is sent
+	_, err = Print(ctx, "", "user", "pass", "ip/address")
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+	"github.com/sumitroajiprabowo/routerosv7-restfull-api/ros"
+)
+
+// namespace is the prefix of the metric names
+const namespace = "routeros"
+
+// newDesc creates the description of a metric labeled by target and the given labels
+func newDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, append([]string{"target"}, labels...),
+		nil)
+}
+
+// Descriptions of the metrics
+var (
+	upDesc             = newDesc("up", "Whether the router answered the scrape.")
+	scrapeDurationDesc = newDesc("scrape_duration_seconds", "Time taken to scrape the router.")
+	scrapeSuccessDesc  = newDesc("scrape_collector_success", "Whether a collector of the router succeeded.",
+		"collector")
+
+	systemInfoDesc = newDesc("system_info", "RouterOS version and board of the router.",
+		"version", "board_name", "architecture")
+	uptimeDesc      = newDesc("system_uptime_seconds", "Time since the router booted.")
+	cpuLoadDesc     = newDesc("system_cpu_load_percent", "CPU load of the router.")
+	freeMemoryDesc  = newDesc("system_memory_free_bytes", "Free memory of the router.")
+	totalMemoryDesc = newDesc("system_memory_total_bytes", "Total memory of the router.")
+	freeDiskDesc    = newDesc("system_disk_free_bytes", "Free disk space of the router.")
+	totalDiskDesc   = newDesc("system_disk_total_bytes", "Total disk space of the router.")
+
+	healthDesc = newDesc("health", "Health sensor of the router, e.g. voltage or temperature.", "name", "unit")
+
+	interfaceRunningDesc = newDesc("interface_running", "Whether the interface is running.", "interface")
+	interfaceCounterDesc = map[string]*prometheus.Desc{
+		"rx-byte":   newDesc("interface_receive_bytes_total", "Bytes received by the interface.", "interface"),
+		"tx-byte":   newDesc("interface_transmit_bytes_total", "Bytes sent by the interface.", "interface"),
+		"rx-packet": newDesc("interface_receive_packets_total", "Packets received by the interface.", "interface"),
+		"tx-packet": newDesc("interface_transmit_packets_total", "Packets sent by the interface.", "interface"),
+		"rx-error":  newDesc("interface_receive_errors_total", "Receive errors of the interface.", "interface"),
+		"tx-error":  newDesc("interface_transmit_errors_total", "Transmit errors of the interface.", "interface"),
+		"rx-drop":   newDesc("interface_receive_drops_total", "Received packets dropped by the interface.", "interface"),
+		"tx-drop":   newDesc("interface_transmit_drops_total", "Sent packets dropped by the interface.", "interface"),
+	}
+
+	dhcpLeasesDesc = newDesc("dhcp_leases", "Number of DHCP leases by server and status.", "server", "status")
+
+	firewallBytesDesc = newDesc("firewall_rule_bytes_total", "Bytes matched by the firewall rule.",
+		"table", "chain", "rule", "comment")
+	firewallPacketsDesc = newDesc("firewall_rule_packets_total", "Packets matched by the firewall rule.",
+		"table", "chain", "rule", "comment")
+)
+
+// firewallTables are the firewall tables whose rule counters are scraped
+var firewallTables = []string{"filter", "nat", "mangle"}
+
+// scrapeFunc scrapes a part of a router and sends its metrics to the channel
+type scrapeFunc func(ctx context.Context, client *routeros.Client, target string, ch chan<- prometheus.Metric) error
+
+// collectors are the scrapes of a router by name, run in order
+var collectors = []struct {
+	name   string
+	scrape scrapeFunc
+}{
+	{"resource", scrapeResource},
+	{"health", scrapeHealth},
+	{"interface", scrapeInterfaces},
+	{"dhcp", scrapeDHCPLeases},
+	{"firewall", scrapeFirewall},
+}
+
+/*
+targetsCollector scrapes the targets when Prometheus collects the metrics. The scrapes of the targets run
+concurrently within the limit of the exporter, the scrapes of a target run one after another to spare the router.
+*/
+type targetsCollector struct {
+	ctx      context.Context // Context of the scrape, ending at the timeout
+	exporter *exporter       // Exporter holding the clients and the concurrency limit
+	targets  []string        // Targets to scrape
+}
+
+// Describe sends no description, the metrics are checked when collected
+func (c *targetsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect scrapes the targets and sends their metrics to the channel
+func (c *targetsCollector) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	for _, target := range c.targets {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			c.collectTarget(target, ch)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// collectTarget scrapes a target once a scrape slot is free and sends its metrics to the channel
+func (c *targetsCollector) collectTarget(target string, ch chan<- prometheus.Metric) {
+	start := time.Now()
+	up := 0.0
+
+	// Wait for a scrape slot
+	if err := c.exporter.acquire(c.ctx); err != nil {
+		c.exporter.logger.Warn("scrape not started", "target", target, "error", err)
+	} else {
+		defer c.exporter.release()
+		up = c.scrapeTarget(target, ch)
+	}
+
+	ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, up, target)
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(start).Seconds(), target)
+}
+
+// scrapeTarget runs the collectors of a target, returning 1 if at least one of them succeeded
+func (c *targetsCollector) scrapeTarget(target string, ch chan<- prometheus.Metric) float64 {
+	client := c.exporter.clients[target]
+
+	up := 0.0
+	for _, collector := range collectors {
+		success := 1.0
+		if err := collector.scrape(c.ctx, client, target, ch); err != nil {
+			c.exporter.logger.Warn("scrape failed", "target", target, "collector", collector.name, "error", err)
+			success = 0
+		} else {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, target, collector.name)
+	}
+	return up
+}
+
+// resource is the system/resource of a router
+type resource struct {
+	Uptime       ros.Duration `json:"uptime"`
+	Version      string       `json:"version"`
+	BoardName    string       `json:"board-name"`
+	Architecture string       `json:"architecture-name"`
+	CPULoad      string       `json:"cpu-load"`
+	FreeMemory   ros.Bytes    `json:"free-memory"`
+	TotalMemory  ros.Bytes    `json:"total-memory"`
+	FreeDisk     ros.Bytes    `json:"free-hdd-space"`
+	TotalDisk    ros.Bytes    `json:"total-hdd-space"`
+}
+
+// scrapeResource scrapes the uptime, CPU load, memory and disk space of the router
+func scrapeResource(ctx context.Context, client *routeros.Client, target string, ch chan<- prometheus.Metric) error {
+	r, err := routeros.PrintAs[resource](ctx, client, "system/resource")
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(systemInfoDesc, prometheus.GaugeValue, 1, target, r.Version, r.BoardName,
+		r.Architecture)
+	ch <- prometheus.MustNewConstMetric(uptimeDesc, prometheus.GaugeValue, r.Uptime.Duration().Seconds(), target)
+	if load, err := strconv.ParseFloat(r.CPULoad, 64); err == nil {
+		ch <- prometheus.MustNewConstMetric(cpuLoadDesc, prometheus.GaugeValue, load, target)
+	}
+	ch <- prometheus.MustNewConstMetric(freeMemoryDesc, prometheus.GaugeValue, float64(r.FreeMemory), target)
+	ch <- prometheus.MustNewConstMetric(totalMemoryDesc, prometheus.GaugeValue, float64(r.TotalMemory), target)
+	ch <- prometheus.MustNewConstMetric(freeDiskDesc, prometheus.GaugeValue, float64(r.FreeDisk), target)
+	ch <- prometheus.MustNewConstMetric(totalDiskDesc, prometheus.GaugeValue, float64(r.TotalDisk), target)
+	return nil
+}
+
+// healthSensor is a sensor of system/health, e.g. {"name":"temperature","value":"35","type":"C"}
+type healthSensor struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// scrapeHealth scrapes the numeric health sensors of the router, routers without sensors return an empty list
+func scrapeHealth(ctx context.Context, client *routeros.Client, target string, ch chan<- prometheus.Metric) error {
+	sensors, err := routeros.PrintAs[[]healthSensor](ctx, client, "system/health")
+	if err != nil {
+		return err
+	}
+
+	for _, sensor := range sensors {
+		value, err := strconv.ParseFloat(sensor.Value, 64)
+		if err != nil {
+			continue // Skip the sensors with a state such as "ok"
+		}
+		ch <- prometheus.MustNewConstMetric(healthDesc, prometheus.GaugeValue, value, target, sensor.Name, sensor.Type)
+	}
+	return nil
+}
+
+// scrapeInterfaces scrapes the traffic and error counters of the interfaces
+func scrapeInterfaces(ctx context.Context, client *routeros.Client, target string, ch chan<- prometheus.Metric) error {
+	fields := []string{"name", "running"}
+	for field := range interfaceCounterDesc {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields[2:]) // Keep the URL stable
+
+	interfaces, err := routeros.PrintAs[[]map[string]string](ctx, client,
+		routeros.NewPath("interface").Proplist(fields...).String())
+	if err != nil {
+		return err
+	}
+
+	for _, iface := range interfaces {
+		name := iface["name"]
+		running, _ := ros.ParseBool(iface["running"])
+		ch <- prometheus.MustNewConstMetric(interfaceRunningDesc, prometheus.GaugeValue, boolValue(bool(running)),
+			target, name)
+
+		for field, desc := range interfaceCounterDesc {
+			if value, err := strconv.ParseFloat(iface[field], 64); err == nil {
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, target, name)
+			}
+		}
+	}
+	return nil
+}
+
+// scrapeDHCPLeases scrapes the number of DHCP leases by server and status
+func scrapeDHCPLeases(ctx context.Context, client *routeros.Client, target string, ch chan<- prometheus.Metric) error {
+	leases, err := routeros.PrintAs[[]map[string]string](ctx, client,
+		routeros.NewPath("ip/dhcp-server/lease").Proplist("server", "status").String())
+	if err != nil {
+		return err
+	}
+
+	// Count the leases
+	type key struct{ server, status string }
+	counts := make(map[key]int)
+	for _, lease := range leases {
+		counts[key{lease["server"], lease["status"]}]++
+	}
+
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(dhcpLeasesDesc, prometheus.GaugeValue, float64(count), target, k.server,
+			k.status)
+	}
+	return nil
+}
+
+// scrapeFirewall scrapes the byte and packet counters of the rules of the firewall tables
+func scrapeFirewall(ctx context.Context, client *routeros.Client, target string, ch chan<- prometheus.Metric) error {
+	for _, table := range firewallTables {
+		rules, err := routeros.PrintAs[[]map[string]string](ctx, client,
+			routeros.NewPath("ip/firewall").Segment(table).Proplist(".id", "chain", "comment", "bytes", "packets").String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+
+		for _, rule := range rules {
+			labels := []string{target, table, rule["chain"], rule[".id"], strings.TrimSpace(rule["comment"])}
+			if value, err := strconv.ParseFloat(rule["bytes"], 64); err == nil {
+				ch <- prometheus.MustNewConstMetric(firewallBytesDesc, prometheus.CounterValue, value, labels...)
+			}
+			if value, err := strconv.ParseFloat(rule["packets"], 64); err == nil {
+				ch <- prometheus.MustNewConstMetric(firewallPacketsDesc, prometheus.CounterValue, value, labels...)
+			}
+		}
+	}
+	return nil
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResponses are the answers of the fake router by path
+var fakeResponses = map[string]string{
+	"/rest/system/resource": `{"uptime":"1d02:03:04","version":"7.14.3 (stable)","board-name":"hEX",` +
+		`"architecture-name":"mmips","cpu-load":"7","free-memory":"201326592","total-memory":"268435456",` +
+		`"free-hdd-space":"4194304","total-hdd-space":"16777216"}`,
+	"/rest/system/health": `[{".id":"*D","name":"voltage","value":"24.1","type":"V"},` +
+		`{".id":"*E","name":"temperature","value":"41","type":"C"},{".id":"*F","name":"psu1-state","value":"ok","type":""}]`,
+	"/rest/interface": `[{"name":"ether1","running":"true","rx-byte":"1000","tx-byte":"2000","rx-packet":"10",` +
+		`"tx-packet":"20","rx-error":"1","tx-error":"0","rx-drop":"2","tx-drop":"0"},` +
+		`{"name":"ether2","running":"false","rx-byte":"0","tx-byte":"0"}]`,
+	"/rest/ip/dhcp-server/lease": `[{"server":"lan","status":"bound"},{"server":"lan","status":"bound"},` +
+		`{"server":"lan","status":"waiting"}]`,
+	"/rest/ip/firewall/filter": `[{".id":"*1","chain":"input","comment":"accept established","bytes":"5000",` +
+		`"packets":"50"},{".id":"*2","chain":"forward","bytes":"0","packets":"0"}]`,
+	"/rest/ip/firewall/nat":    `[{".id":"*3","chain":"srcnat","comment":"masquerade","bytes":"9000","packets":"90"}]`,
+	"/rest/ip/firewall/mangle": `[]`,
+}
+
+/*
+setupFakeRouter creates a REST server answering like a RouterOS router for the user "monitor" with the password
+"secret", counting the requests. The handler is called before answering, e.g. to delay the answers.
+*/
+func setupFakeRouter(t *testing.T, handler func(r *http.Request)) (*httptest.Server, *atomic.Int64) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if handler != nil {
+			handler(r)
+		}
+
+		// Check the credentials
+		if username, password, ok := r.BasicAuth(); !ok || username != "monitor" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":401,"message":"Unauthorized"}`))
+			return
+		}
+
+		// Answer the path
+		body, ok := fakeResponses[r.URL.Path]
+		if !ok || r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":404,"message":"Not Found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// fakeTarget returns the target of the fake router
+func fakeTarget(server *httptest.Server) string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// newTestExporter creates an exporter of the targets with the credentials of the fake router
+func newTestExporter(t *testing.T, concurrency int, targets ...string) *exporter {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	e, err := newExporter(config{
+		targets:     targets,
+		username:    "monitor",
+		password:    "secret",
+		timeout:     time.Second,
+		concurrency: concurrency,
+	})
+	require.NoError(t, err)
+	return e
+}
+
+// TestTargetsCollector tests the metrics scraped from a router
+func TestTargetsCollector(t *testing.T) {
+	server, _ := setupFakeRouter(t, nil)
+	target := fakeTarget(server)
+	e := newTestExporter(t, 1, target)
+
+	collector := &targetsCollector{ctx: context.Background(), exporter: e, targets: []string{target}}
+	expected := strings.ReplaceAll(`
+# HELP routeros_up Whether the router answered the scrape.
+# TYPE routeros_up gauge
+routeros_up{target="TARGET"} 1
+# HELP routeros_system_info RouterOS version and board of the router.
+# TYPE routeros_system_info gauge
+routeros_system_info{architecture="mmips",board_name="hEX",target="TARGET",version="7.14.3 (stable)"} 1
+# HELP routeros_system_uptime_seconds Time since the router booted.
+# TYPE routeros_system_uptime_seconds gauge
+routeros_system_uptime_seconds{target="TARGET"} 93784
+# HELP routeros_system_cpu_load_percent CPU load of the router.
+# TYPE routeros_system_cpu_load_percent gauge
+routeros_system_cpu_load_percent{target="TARGET"} 7
+# HELP routeros_system_memory_free_bytes Free memory of the router.
+# TYPE routeros_system_memory_free_bytes gauge
+routeros_system_memory_free_bytes{target="TARGET"} 2.01326592e+08
+# HELP routeros_health Health sensor of the router, e.g. voltage or temperature.
+# TYPE routeros_health gauge
+routeros_health{name="temperature",target="TARGET",unit="C"} 41
+routeros_health{name="voltage",target="TARGET",unit="V"} 24.1
+# HELP routeros_interface_receive_bytes_total Bytes received by the interface.
+# TYPE routeros_interface_receive_bytes_total counter
+routeros_interface_receive_bytes_total{interface="ether1",target="TARGET"} 1000
+routeros_interface_receive_bytes_total{interface="ether2",target="TARGET"} 0
+# HELP routeros_interface_running Whether the interface is running.
+# TYPE routeros_interface_running gauge
+routeros_interface_running{interface="ether1",target="TARGET"} 1
+routeros_interface_running{interface="ether2",target="TARGET"} 0
+# HELP routeros_dhcp_leases Number of DHCP leases by server and status.
+# TYPE routeros_dhcp_leases gauge
+routeros_dhcp_leases{server="lan",status="bound",target="TARGET"} 2
+routeros_dhcp_leases{server="lan",status="waiting",target="TARGET"} 1
+# HELP routeros_firewall_rule_bytes_total Bytes matched by the firewall rule.
+# TYPE routeros_firewall_rule_bytes_total counter
+routeros_firewall_rule_bytes_total{chain="forward",comment="",rule="*2",table="filter",target="TARGET"} 0
+routeros_firewall_rule_bytes_total{chain="input",comment="accept established",rule="*1",table="filter",target="TARGET"} 5000
+routeros_firewall_rule_bytes_total{chain="srcnat",comment="masquerade",rule="*3",table="nat",target="TARGET"} 9000
+# HELP routeros_scrape_collector_success Whether a collector of the router succeeded.
+# TYPE routeros_scrape_collector_success gauge
+routeros_scrape_collector_success{collector="dhcp",target="TARGET"} 1
+routeros_scrape_collector_success{collector="firewall",target="TARGET"} 1
+routeros_scrape_collector_success{collector="health",target="TARGET"} 1
+routeros_scrape_collector_success{collector="interface",target="TARGET"} 1
+routeros_scrape_collector_success{collector="resource",target="TARGET"} 1
+`, "TARGET", target)
+
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"routeros_up", "routeros_system_info", "routeros_system_uptime_seconds", "routeros_system_cpu_load_percent",
+		"routeros_system_memory_free_bytes", "routeros_health", "routeros_interface_receive_bytes_total",
+		"routeros_interface_running", "routeros_dhcp_leases", "routeros_firewall_rule_bytes_total",
+		"routeros_scrape_collector_success")
+	assert.NoError(t, err)
+}
+
+// TestTargetsCollector_Unauthorized tests that a router rejecting the credentials is down
+func TestTargetsCollector_Unauthorized(t *testing.T) {
+	server, _ := setupFakeRouter(t, nil)
+	target := fakeTarget(server)
+
+	e, err := newExporter(config{targets: []string{target}, username: "monitor", password: "wrong",
+		timeout: time.Second, concurrency: 1})
+	require.NoError(t, err)
+
+	collector := &targetsCollector{ctx: context.Background(), exporter: e, targets: []string{target}}
+	expected := `
+# HELP routeros_up Whether the router answered the scrape.
+# TYPE routeros_up gauge
+routeros_up{target="` + target + `"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "routeros_up"))
+}
+
+// TestTargetsCollector_Concurrency tests that no more routers than the limit are scraped at the same time
+func TestTargetsCollector_Concurrency(t *testing.T) {
+	var current, peak atomic.Int64
+	slow := func(*http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var targets []string
+	for i := 0; i < 3; i++ {
+		server, _ := setupFakeRouter(t, slow)
+		targets = append(targets, fakeTarget(server))
+	}
+	e := newTestExporter(t, 1, targets...)
+
+	collector := &targetsCollector{ctx: context.Background(), exporter: e, targets: targets}
+	assert.Positive(t, testutil.CollectAndCount(collector, "routeros_up"))
+	assert.Equal(t, int64(1), peak.Load())
+}
+
+// TestTargetsCollector_Timeout tests that a hanging router is reported down once the scrape times out
+func TestTargetsCollector_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := setupFakeRouter(t, func(r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+	target := fakeTarget(server)
+	e := newTestExporter(t, 1, target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	collector := &targetsCollector{ctx: ctx, exporter: e, targets: []string{target}}
+	expected := `
+# HELP routeros_up Whether the router answered the scrape.
+# TYPE routeros_up gauge
+routeros_up{target="` + target + `"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "routeros_up"))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
+
+// scrapeTimeoutOffset is removed from the scrape timeout of Prometheus to answer before it gives up
+const scrapeTimeoutOffset = 500 * time.Millisecond
+
+// config is the configuration of the exporter
+type config struct {
+	targets     []string          // Routers to scrape, e.g. "https://192.168.88.1"
+	username    string            // Username of the REST API
+	password    string            // Password of the REST API
+	timeout     time.Duration     // Maximum time of a scrape
+	concurrency int               // Maximum number of routers scraped at the same time
+	options     []routeros.Option // Options of the clients of the routers
+	logger      *slog.Logger      // Logger of the exporter
+}
+
+// exporter serves the metrics of the routers
+type exporter struct {
+	config
+	slots   chan struct{}               // Semaphore of the routers scraped at the same time
+	clients map[string]*routeros.Client // Clients by target, reused between scrapes
+}
+
+// newExporter creates an exporter for the configuration, with a client for every target
+func newExporter(cfg config) (*exporter, error) {
+
+	// Check the configuration
+	switch {
+	case len(cfg.targets) == 0:
+		return nil, errors.New("no target")
+	case cfg.timeout <= 0:
+		return nil, errors.New("timeout must be positive")
+	case cfg.concurrency < 1:
+		return nil, errors.New("concurrency must be positive")
+	}
+
+	// Log to the default logger unless a logger is provided
+	if cfg.logger == nil {
+		cfg.logger = slog.Default()
+	}
+
+	// Create the clients of the targets
+	clients := make(map[string]*routeros.Client, len(cfg.targets))
+	for _, target := range cfg.targets {
+		opts := append([]routeros.Option{routeros.WithCredentials(cfg.username, cfg.password)}, cfg.options...)
+		client, err := routeros.NewClient(target, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", target, err)
+		}
+		clients[target] = client
+	}
+
+	return &exporter{config: cfg, slots: make(chan struct{}, cfg.concurrency), clients: clients}, nil
+}
+
+// acquire waits for a scrape slot until the context ends
+func (e *exporter) acquire(ctx context.Context) error {
+	select {
+	case e.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a scrape slot
+func (e *exporter) release() {
+	<-e.slots
+}
+
+// isTarget checks if the target is one of the configured targets
+func (e *exporter) isTarget(target string) bool {
+	_, ok := e.clients[target]
+	return ok
+}
+
+/*
+ServeHTTP scrapes the routers and serves their metrics. Every configured router is scraped, or the router given by
+the target parameter only, e.g. /metrics?target=https://192.168.88.1, which must be one of the configured routers.
+*/
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	targets := e.targets
+
+	// Check if a single target is requested
+	if target := r.URL.Query().Get("target"); target != "" {
+		if !e.isTarget(target) {
+			http.Error(w, fmt.Sprintf("unknown target %q", target), http.StatusBadRequest)
+			return
+		}
+		targets = []string{target}
+	}
+
+	// Scrape within the timeout
+	ctx, cancel := context.WithTimeout(r.Context(), e.scrapeTimeout(r))
+	defer cancel()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(&targetsCollector{ctx: ctx, exporter: e, targets: targets})
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: slog.NewLogLogger(e.logger.Handler(),
+		slog.LevelError)}).ServeHTTP(w, r)
+}
+
+// scrapeTimeout returns the timeout of the exporter, shortened to the scrape timeout announced by Prometheus if any
+func (e *exporter) scrapeTimeout(r *http.Request) time.Duration {
+	timeout := e.timeout
+
+	// Check if Prometheus announced its scrape timeout
+	seconds, err := strconv.ParseFloat(r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64)
+	if err != nil || seconds <= 0 {
+		return timeout
+	}
+	announced := time.Duration(seconds * float64(time.Second))
+	if announced > scrapeTimeoutOffset {
+		announced -= scrapeTimeoutOffset
+	}
+	if announced < timeout {
+		timeout = announced
+	}
+	return timeout
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape serves a scrape of the exporter with the query and returns the recorded response
+func scrape(e *exporter, query string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics"+query, nil))
+	return recorder
+}
+
+// TestExporter_ServeHTTP tests that every configured router is scraped
+func TestExporter_ServeHTTP(t *testing.T) {
+	first, _ := setupFakeRouter(t, nil)
+	second, _ := setupFakeRouter(t, nil)
+	e := newTestExporter(t, 2, fakeTarget(first), fakeTarget(second))
+
+	response := scrape(e, "")
+	require.Equal(t, http.StatusOK, response.Code)
+	body := response.Body.String()
+	assert.Contains(t, body, `routeros_up{target="`+fakeTarget(first)+`"} 1`)
+	assert.Contains(t, body, `routeros_up{target="`+fakeTarget(second)+`"} 1`)
+	assert.Contains(t, body, `routeros_scrape_duration_seconds{target="`+fakeTarget(first)+`"}`)
+}
+
+// TestExporter_ServeHTTP_Target tests that the target parameter scrapes a single router
+func TestExporter_ServeHTTP_Target(t *testing.T) {
+	first, _ := setupFakeRouter(t, nil)
+	second, requests := setupFakeRouter(t, nil)
+	e := newTestExporter(t, 1, fakeTarget(first), fakeTarget(second))
+
+	response := scrape(e, "?target="+url.QueryEscape(fakeTarget(first)))
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `routeros_up{target="`+fakeTarget(first)+`"} 1`)
+	assert.NotContains(t, response.Body.String(), fakeTarget(second))
+	assert.Zero(t, requests.Load())
+}
+
+// TestExporter_ServeHTTP_UnknownTarget tests that a router which is not configured is rejected
+func TestExporter_ServeHTTP_UnknownTarget(t *testing.T) {
+	server, _ := setupFakeRouter(t, nil)
+	e := newTestExporter(t, 1, fakeTarget(server))
+
+	response := scrape(e, "?target=10.0.0.1")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), `unknown target "10.0.0.1"`)
+}
+
+// TestExporter_ServeHTTP_Down tests that an unreachable router is reported down without failing the scrape
+func TestExporter_ServeHTTP_Down(t *testing.T) {
+	up, _ := setupFakeRouter(t, nil)
+	down, _ := setupFakeRouter(t, nil)
+	down.Close()
+	e := newTestExporter(t, 2, fakeTarget(up), fakeTarget(down))
+
+	response := scrape(e, "")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `routeros_up{target="`+fakeTarget(up)+`"} 1`)
+	assert.Contains(t, response.Body.String(), `routeros_up{target="`+fakeTarget(down)+`"} 0`)
+}
+
+// TestExporter_ScrapeTimeout tests the timeout announced by Prometheus
+func TestExporter_ScrapeTimeout(t *testing.T) {
+	e := &exporter{config: config{timeout: 10 * time.Second}}
+
+	tests := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", 10 * time.Second},
+		{"invalid", 10 * time.Second},
+		{"-1", 10 * time.Second},
+		{"5", 4500 * time.Millisecond},
+		{"0.2", 200 * time.Millisecond},
+		{"60", 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if test.header != "" {
+			request.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", test.header)
+		}
+		assert.Equal(t, test.expected, e.scrapeTimeout(request), test.header)
+	}
+}
+
+// TestNewExporter_Errors tests the validation of the configuration
+func TestNewExporter_Errors(t *testing.T) {
+	valid := config{targets: []string{"192.168.88.1"}, username: "monitor", timeout: time.Second, concurrency: 1}
+
+	noTarget := valid
+	noTarget.targets = nil
+	_, err := newExporter(noTarget)
+	assert.EqualError(t, err, "no target")
+
+	noTimeout := valid
+	noTimeout.timeout = 0
+	_, err = newExporter(noTimeout)
+	assert.EqualError(t, err, "timeout must be positive")
+
+	noConcurrency := valid
+	noConcurrency.concurrency = 0
+	_, err = newExporter(noConcurrency)
+	assert.EqualError(t, err, "concurrency must be positive")
+
+	invalidTarget := valid
+	invalidTarget.targets = []string{"ftp://192.168.88.1"}
+	_, err = newExporter(invalidTarget)
+	assert.ErrorContains(t, err, "target ftp://192.168.88.1")
+
+	e, err := newExporter(valid)
+	require.NoError(t, err)
+	assert.NotNil(t, e.logger)
+	assert.True(t, e.isTarget("192.168.88.1"))
+}
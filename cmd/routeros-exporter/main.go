@@ -0,0 +1,111 @@
+/*
+Command routeros-exporter is a Prometheus exporter for RouterOS v7 routers built on the REST API client.
+It scrapes system/resource, system/health, the interface counters, the DHCP lease counts and the firewall rule
+counters of the configured routers and serves them on /metrics. A single router is scraped with
+/metrics?target=<router>, like the blackbox exporter.
+usage:
+ROUTEROS_PASSWORD=secret routeros-exporter -targets https://192.168.88.1,https://10.0.0.1 -username monitor
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	// Parse the configuration
+	listen, cfg, err := parseConfig(os.Args[1:], os.Getenv, os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	cfg.logger = logger
+
+	// Create the exporter
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(2)
+	}
+
+	// Serve the metrics
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	logger.Info("listening", "address", listen, "targets", len(cfg.targets))
+	if err := server.ListenAndServe(); err != nil {
+		logger.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+/*
+parseConfig parses the command line arguments, reading the credentials from the ROUTEROS_USERNAME and
+ROUTEROS_PASSWORD environment variables unless given as flags, and returns the listen address and the configuration
+*/
+func parseConfig(args []string, getenv func(string) string, output io.Writer) (string, config, error) {
+	flags := flag.NewFlagSet("routeros-exporter", flag.ContinueOnError)
+	flags.SetOutput(output)
+
+	listen := flags.String("listen", ":9436", "Address to serve the metrics on")
+	targets := flags.String("targets", "", "Comma-separated routers to scrape, e.g. https://192.168.88.1")
+	username := flags.String("username", getenv("ROUTEROS_USERNAME"), "Username of the REST API")
+	password := flags.String("password", getenv("ROUTEROS_PASSWORD"), "Password of the REST API, "+
+		"prefer the ROUTEROS_PASSWORD environment variable")
+	timeout := flags.Duration("timeout", 10*time.Second, "Maximum time of a scrape")
+	concurrency := flags.Int("concurrency", 4, "Maximum number of routers scraped at the same time")
+	maxInFlight := flags.Int("max-in-flight", 1, "Maximum number of concurrent requests to a router")
+	caFile := flags.String("ca-file", "", "PEM file of the CA certificates of the routers")
+	insecure := flags.Bool("insecure-skip-verify", false, "Skip the verification of the router certificates")
+
+	if err := flags.Parse(args); err != nil {
+		return "", config{}, err
+	}
+
+	cfg := config{
+		targets:     splitTargets(*targets),
+		username:    *username,
+		password:    *password,
+		timeout:     *timeout,
+		concurrency: *concurrency,
+		options:     []routeros.Option{routeros.WithMaxInFlight(*maxInFlight)},
+	}
+	if *caFile != "" {
+		cfg.options = append(cfg.options, routeros.WithRootCAsFile(*caFile))
+	}
+	if *insecure {
+		cfg.options = append(cfg.options, routeros.WithInsecureSkipVerify())
+	}
+
+	// Check if the username is set
+	if cfg.username == "" {
+		return "", config{}, errors.New("missing username, set -username or ROUTEROS_USERNAME")
+	}
+	return *listen, cfg, nil
+}
+
+// splitTargets splits the comma-separated targets, skipping the empty ones
+func splitTargets(targets string) []string {
+	var result []string
+	for _, target := range strings.Split(targets, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			result = append(result, target)
+		}
+	}
+	return result
+}
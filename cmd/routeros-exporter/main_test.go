@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// environment returns a getenv function reading the variables
+func environment(variables map[string]string) func(string) string {
+	return func(name string) string {
+		return variables[name]
+	}
+}
+
+// TestParseConfig tests the flags and the defaults
+func TestParseConfig(t *testing.T) {
+	listen, cfg, err := parseConfig([]string{
+		"-targets", "https://192.168.88.1, 10.0.0.1,,",
+		"-username", "monitor",
+		"-timeout", "5s",
+		"-concurrency", "8",
+		"-insecure-skip-verify",
+	}, environment(map[string]string{"ROUTEROS_PASSWORD": "secret"}), &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9436", listen)
+	assert.Equal(t, []string{"https://192.168.88.1", "10.0.0.1"}, cfg.targets)
+	assert.Equal(t, "monitor", cfg.username)
+	assert.Equal(t, "secret", cfg.password)
+	assert.Equal(t, 5*time.Second, cfg.timeout)
+	assert.Equal(t, 8, cfg.concurrency)
+	assert.Len(t, cfg.options, 2)
+}
+
+// TestParseConfig_Environment tests the credentials read from the environment
+func TestParseConfig_Environment(t *testing.T) {
+	_, cfg, err := parseConfig([]string{"-targets", "192.168.88.1", "-listen", ":9000"},
+		environment(map[string]string{"ROUTEROS_USERNAME": "monitor", "ROUTEROS_PASSWORD": "secret"}),
+		&bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, "monitor", cfg.username)
+	assert.Equal(t, "secret", cfg.password)
+
+	// The flags take precedence over the environment
+	_, cfg, err = parseConfig([]string{"-targets", "192.168.88.1", "-username", "admin"},
+		environment(map[string]string{"ROUTEROS_USERNAME": "monitor"}), &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", cfg.username)
+}
+
+// TestParseConfig_Errors tests the invalid arguments
+func TestParseConfig_Errors(t *testing.T) {
+	_, _, err := parseConfig([]string{"-targets", "192.168.88.1"}, environment(nil), &bytes.Buffer{})
+	assert.EqualError(t, err, "missing username, set -username or ROUTEROS_USERNAME")
+
+	_, _, err = parseConfig([]string{"-unknown"}, environment(nil), &bytes.Buffer{})
+	assert.Error(t, err)
+
+	var output bytes.Buffer
+	_, _, err = parseConfig([]string{"-h"}, environment(nil), &output)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	assert.Contains(t, output.String(), "-targets")
+}
+
+// TestSplitTargets tests the splitting of the comma-separated targets
+func TestSplitTargets(t *testing.T) {
+	assert.Nil(t, splitTargets(""))
+	assert.Equal(t, []string{"a", "b"}, splitTargets(" a ,,b, "))
+}
@@ -0,0 +1,131 @@
+package routerosv7_restfull_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSON shapes reported by ShapeError
+const (
+	shapeArray  = "array"  // shapeArray is a JSON array, returned by RouterOS for lists of items
+	shapeObject = "object" // shapeObject is a JSON object, returned by RouterOS for a single item
+	shapeValue  = "value"  // shapeValue is any other JSON value
+)
+
+/*
+ShapeError is wrapped in a DecodeError when the router returns a JSON object where the caller
+expected a list, or a JSON array where the caller expected a single item
+*/
+type ShapeError struct {
+	Expected string // Shape expected by the caller, "array" or "object"
+	Actual   string // Shape returned by the router, "array", "object" or "value"
+}
+
+// Error returns the expected and actual shapes
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("expected JSON %s, got %s", e.Expected, e.Actual)
+}
+
+// PrintAs creates a new GET request and decodes the result into T, e.g. []Address for "ip/address".
+func PrintAs[T any](ctx context.Context, c *Client, command string) (T, error) {
+	return executeAs[T](ctx, c, MethodGet, command, nil)
+}
+
+// AddAs creates a new PUT request and decodes the created item into T.
+func AddAs[T any](ctx context.Context, c *Client, command string, payload []byte) (T, error) {
+	return executeAs[T](ctx, c, MethodPut, command, payload)
+}
+
+// SetAs creates a new PATCH request and decodes the updated item into T.
+func SetAs[T any](ctx context.Context, c *Client, command string, payload []byte) (T, error) {
+	return executeAs[T](ctx, c, MethodPatch, command, payload)
+}
+
+// RunAs creates a new POST request and decodes the command output into T.
+func RunAs[T any](ctx context.Context, c *Client, command string, payload []byte) (T, error) {
+	return executeAs[T](ctx, c, MethodPost, command, payload)
+}
+
+// executeAs executes the request with the client and decodes the response body into T
+func executeAs[T any](ctx context.Context, c *Client, method, command string, payload []byte) (T, error) {
+	var result T
+
+	// Fetch the raw response body
+	body, config, err := c.fetch(ctx, method, command, payload)
+	if err != nil {
+		return result, err // Return the zero value and error
+	}
+
+	// Decode the response body into the result
+	if err := decodeInto(body, &result); err != nil {
+		return result, &DecodeError{Method: config.Method, URL: config.URL, Err: err}
+	}
+
+	// Return the result and nil error
+	return result, nil
+}
+
+/*
+decodeInto decodes the JSON body into the value pointed to by target after checking that
+the shape of the body matches the shape of the target. An empty body leaves the target untouched.
+*/
+func decodeInto(body []byte, target interface{}) error {
+
+	// Check if the body is empty
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+
+	// Check if the shape of the body matches the shape of the target
+	expected := expectedShape(reflect.TypeOf(target).Elem())
+	if actual := jsonShape(body); expected != "" && expected != actual {
+		return &ShapeError{Expected: expected, Actual: actual}
+	}
+
+	// Decode the body into the target
+	return json.Unmarshal(body, target)
+}
+
+/*
+expectedShape returns the JSON shape a Go type decodes from, or an empty string if the type accepts any shape
+(interfaces and types implementing json.Unmarshaler)
+*/
+func expectedShape(t reflect.Type) string {
+
+	// Dereference pointers
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Types with custom decoding accept any shape
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return shapeArray
+	case reflect.Struct, reflect.Map:
+		return shapeObject
+	case reflect.Interface:
+		return ""
+	default:
+		return shapeValue
+	}
+}
+
+// jsonShape returns the shape of a non-empty JSON document from its first byte
+func jsonShape(body []byte) string {
+	switch body[0] {
+	case '[':
+		return shapeArray
+	case '{':
+		return shapeObject
+	default:
+		return shapeValue
+	}
+}
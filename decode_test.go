@@ -0,0 +1,162 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// address is a typed ip/address item used by the decoding tests
+type address struct {
+	ID        string `json:".id"`
+	Address   string `json:"address"`
+	Interface string `json:"interface"`
+}
+
+// newMockClient sets up a mock server with a status code and response body and a client with the options talking to it
+func newMockClient(t *testing.T, statusCode int, responseBody string, opts ...Option) *Client {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	server := setupMockServer(statusCode, responseBody)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(serverHost(server), opts...)
+	require.NoError(t, err)
+
+	return client
+}
+
+// TestPrintAs_Slice tests decoding a list of items into a slice of structs
+func TestPrintAs_Slice(t *testing.T) {
+	client := newMockClient(t, http.StatusOK,
+		`[{".id":"*1","address":"192.168.88.1/24","interface":"ether1"},{".id":"*2","address":"10.0.0.1/8","interface":"ether2"}]`)
+
+	result, err := PrintAs[[]address](context.Background(), client, "ip/address")
+
+	require.NoError(t, err)
+	assert.Equal(t, []address{
+		{ID: "*1", Address: "192.168.88.1/24", Interface: "ether1"},
+		{ID: "*2", Address: "10.0.0.1/8", Interface: "ether2"},
+	}, result)
+}
+
+// TestPrintAs_Struct tests decoding a single item into a struct
+func TestPrintAs_Struct(t *testing.T) {
+	client := newMockClient(t, http.StatusOK, `{".id":"*1","address":"192.168.88.1/24","interface":"ether1"}`)
+
+	result, err := PrintAs[*address](context.Background(), client, "ip/address/*1")
+
+	require.NoError(t, err)
+	assert.Equal(t, &address{ID: "*1", Address: "192.168.88.1/24", Interface: "ether1"}, result)
+}
+
+// TestPrintAs_ShapeMismatch tests the errors returned when the shape of the body does not match the target
+func TestPrintAs_ShapeMismatch(t *testing.T) {
+	t.Run("Object where list expected", func(t *testing.T) {
+		client := newMockClient(t, http.StatusOK, `{".id":"*1"}`)
+
+		_, err := PrintAs[[]address](context.Background(), client, "ip/address")
+
+		var shapeErr *ShapeError
+		require.True(t, errors.As(err, &shapeErr))
+		assert.Equal(t, &ShapeError{Expected: "array", Actual: "object"}, shapeErr)
+		assert.EqualError(t, err, "decode error: expected JSON array, got object")
+	})
+
+	t.Run("List where object expected", func(t *testing.T) {
+		client := newMockClient(t, http.StatusOK, `[{".id":"*1"}]`)
+
+		_, err := PrintAs[address](context.Background(), client, "ip/address/*1")
+
+		var shapeErr *ShapeError
+		require.True(t, errors.As(err, &shapeErr))
+		assert.Equal(t, &ShapeError{Expected: "object", Actual: "array"}, shapeErr)
+
+		var decodeErr *DecodeError
+		assert.True(t, errors.As(err, &decodeErr))
+	})
+}
+
+// TestRunAs_Map tests decoding into a map and into an interface accepting any shape
+func TestRunAs_Map(t *testing.T) {
+	client := newMockClient(t, http.StatusOK, `[{"address":"192.168.88.1/24"}]`)
+
+	result, err := RunAs[[]map[string]string](context.Background(), client, "ip/address/print", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"address": "192.168.88.1/24"}}, result)
+
+	raw, err := RunAs[json.RawMessage](context.Background(), client, "ip/address/print", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"address":"192.168.88.1/24"}]`, string(raw))
+
+	anything, err := RunAs[interface{}](context.Background(), client, "ip/address/print", nil)
+	require.NoError(t, err)
+	assert.Len(t, anything, 1)
+}
+
+// TestAddAs_SetAs tests decoding the item returned by PUT and PATCH requests
+func TestAddAs_SetAs(t *testing.T) {
+	client := newMockClient(t, http.StatusOK, `{".id":"*3","address":"192.168.99.1/24","interface":"ether1"}`)
+	payload := []byte(`{"address":"192.168.99.1/24","interface":"ether1"}`)
+
+	added, err := AddAs[address](context.Background(), client, "ip/address", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "*3", added.ID)
+
+	updated, err := SetAs[address](context.Background(), client, "ip/address/*3", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.99.1/24", updated.Address)
+}
+
+// TestPrintAs_APIError tests that the APIError is returned unchanged with the zero value
+func TestPrintAs_APIError(t *testing.T) {
+	client := newMockClient(t, http.StatusNotFound, `{"error":404,"message":"Not Found"}`)
+
+	result, err := PrintAs[[]address](context.Background(), client, "ip/address")
+
+	assert.Nil(t, result)
+	assert.True(t, IsNotFound(err))
+}
+
+// TestDecodeInto_EmptyBody tests that an empty body leaves the target untouched
+func TestDecodeInto_EmptyBody(t *testing.T) {
+	var result []address
+
+	assert.NoError(t, decodeInto([]byte(" \n"), &result))
+	assert.Nil(t, result)
+}
+
+// TestExpectedShape tests the expected shape of various Go types
+func TestExpectedShape(t *testing.T) {
+	var target struct {
+		Slice   []address
+		Pointer *address
+		Map     map[string]string
+		Any     interface{}
+		Raw     json.RawMessage
+		String  string
+	}
+
+	assert.Equal(t, shapeArray, expectedShape(reflect.TypeOf(&target.Slice).Elem()))
+	assert.Equal(t, shapeObject, expectedShape(reflect.TypeOf(&target.Pointer).Elem()))
+	assert.Equal(t, shapeObject, expectedShape(reflect.TypeOf(&target.Map).Elem()))
+	assert.Equal(t, "", expectedShape(reflect.TypeOf(&target.Any).Elem()))
+	assert.Equal(t, "", expectedShape(reflect.TypeOf(&target.Raw).Elem()))
+	assert.Equal(t, shapeValue, expectedShape(reflect.TypeOf(&target.String).Elem()))
+}
+
+// TestExecuteRequest_EmptyBody tests that an empty successful response decodes to a nil result
+func TestExecuteRequest_EmptyBody(t *testing.T) {
+	client := newMockClient(t, http.StatusNoContent, "")
+
+	result, err := client.Remove(context.Background(), "ip/address/*1")
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
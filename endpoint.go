@@ -0,0 +1,189 @@
+package routerosv7_restfull_api
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hostnamePattern matches the hostnames accepted in an endpoint
+var hostnamePattern = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_.-]*[a-z0-9_.])?$`)
+
+// EndpointError is returned when an endpoint cannot be parsed
+type EndpointError struct {
+	Input  string // Endpoint as given by the caller
+	Reason string // Reason why the endpoint was rejected
+}
+
+// Error returns the endpoint and the reason why it was rejected
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("endpoint %q: %s", e.Input, e.Reason)
+}
+
+/*
+Endpoint is the address of the REST API of a Mikrotik Router.
+Scheme is empty when the endpoint does not specify one, in which case EffectiveScheme picks HTTPS for port 443
+and HTTP otherwise. Host is a hostname, an IPv4 address or an IPv6 address without brackets, optionally
+with a zone such as "fe80::1%eth0". Port is empty for the default port of the scheme.
+*/
+type Endpoint struct {
+	Scheme string // Scheme of the endpoint, "http", "https" or empty
+	Host   string // Hostname or IP address of the endpoint
+	Port   string // Port of the endpoint, empty for the default port of the scheme
+}
+
+/*
+ParseEndpoint parses and normalizes an endpoint given as host, host:port, [ipv6]:port or as a URL with
+an http or https scheme.
+example:
+
+	ParseEndpoint("192.168.88.1")
+	ParseEndpoint("router.lan:8080")
+	ParseEndpoint("[fe80::1%eth0]:8443")
+	ParseEndpoint("https://router.lan")
+*/
+func ParseEndpoint(input string) (Endpoint, error) {
+	var endpoint Endpoint
+	rest := strings.TrimSpace(input)
+
+	// Check if the endpoint has a scheme
+	if scheme, hostPort, found := strings.Cut(rest, "://"); found {
+		scheme = strings.ToLower(scheme)
+		if scheme != httpProtocol && scheme != httpsProtocol {
+			return Endpoint{}, &EndpointError{Input: input, Reason: fmt.Sprintf("unsupported scheme %q", scheme)}
+		}
+		endpoint.Scheme = scheme
+		rest = strings.TrimSuffix(hostPort, "/")
+	}
+
+	// Check if the endpoint contains anything else than a host and a port
+	if rest == "" {
+		return Endpoint{}, &EndpointError{Input: input, Reason: "empty host"}
+	}
+	if i := strings.IndexAny(rest, "/?#@ "); i >= 0 {
+		return Endpoint{}, &EndpointError{Input: input, Reason: fmt.Sprintf("unexpected %q", rest[i])}
+	}
+
+	// Split the host and the port
+	host, port, err := splitEndpointHostPort(rest)
+	if err != nil {
+		return Endpoint{}, &EndpointError{Input: input, Reason: err.Error()}
+	}
+
+	// Validate the port
+	if port != "" {
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return Endpoint{}, &EndpointError{Input: input, Reason: fmt.Sprintf("invalid port %q", port)}
+		}
+	}
+
+	// Normalize IP addresses, e.g. "FE80:0::1" to "fe80::1", brackets are only allowed around IPv6 addresses
+	if strings.Contains(host, ":") || strings.HasPrefix(rest, "[") {
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !addr.Is6() {
+			return Endpoint{}, &EndpointError{Input: input, Reason: fmt.Sprintf("invalid IPv6 address %q", host)}
+		}
+		host = addr.String()
+	} else if addr, err := netip.ParseAddr(host); err == nil {
+		host = addr.String()
+	} else if host = strings.ToLower(host); !hostnamePattern.MatchString(host) {
+		return Endpoint{}, &EndpointError{Input: input, Reason: fmt.Sprintf("invalid hostname %q", host)}
+	}
+
+	endpoint.Host = host
+	endpoint.Port = port
+	return endpoint, nil
+}
+
+/*
+splitEndpointHostPort splits host:port, [ipv6]:port, [ipv6] or a bare IPv6 address into a host and a port.
+A zone given in URL form, such as "%25eth0", is decoded.
+*/
+func splitEndpointHostPort(hostPort string) (string, string, error) {
+
+	// Check if the host is a bracketed IPv6 address
+	if strings.HasPrefix(hostPort, "[") {
+		end := strings.Index(hostPort, "]")
+		if end < 0 {
+			return "", "", fmt.Errorf("missing \"]\"")
+		}
+		host := strings.Replace(hostPort[1:end], "%25", "%", 1)
+		switch after := hostPort[end+1:]; {
+		case after == "":
+			return host, "", nil
+		case strings.HasPrefix(after, ":"):
+			return host, after[1:], nil
+		default:
+			return "", "", fmt.Errorf("unexpected %q after \"]\"", after)
+		}
+	}
+
+	// Check if the host is a bare IPv6 address, which cannot have a port
+	if strings.Count(hostPort, ":") > 1 {
+		return strings.Replace(hostPort, "%25", "%", 1), "", nil
+	}
+
+	// Split the host and the port if any
+	host, port, found := strings.Cut(hostPort, ":")
+	if found && port == "" {
+		return "", "", fmt.Errorf("empty port")
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("empty host")
+	}
+	return host, port, nil
+}
+
+// EffectiveScheme returns the scheme of the endpoint, or HTTPS for port 443 and HTTP otherwise when it is empty
+func (e Endpoint) EffectiveScheme() string {
+	if e.Scheme != "" {
+		return e.Scheme
+	}
+	if e.Port == "443" {
+		return httpsProtocol
+	}
+	return httpProtocol
+}
+
+// WithScheme returns a copy of the endpoint with the given scheme
+func (e Endpoint) WithScheme(scheme string) Endpoint {
+	e.Scheme = scheme
+	return e
+}
+
+// HostPort returns the host and the port, if any, as used in a URL, e.g. "[fe80::1%25eth0]:8443"
+func (e Endpoint) HostPort() string {
+	host := e.Host
+	if strings.Contains(host, ":") {
+		host = "[" + strings.Replace(host, "%", "%25", 1) + "]"
+	}
+	if e.Port == "" {
+		return host
+	}
+	return host + ":" + e.Port
+}
+
+// Address returns the host and port to dial, using the default port of the scheme when the port is empty
+func (e Endpoint) Address() string {
+	port := e.Port
+	if port == "" {
+		port = "80"
+		if e.EffectiveScheme() == httpsProtocol {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(e.Host, port)
+}
+
+// String returns the endpoint as a URL without path, e.g. "https://router.lan:8443"
+func (e Endpoint) String() string {
+	return e.EffectiveScheme() + "://" + e.HostPort()
+}
+
+// URL returns the REST API URL of the command, escaping the command like APIRequest.URL
+func (e Endpoint) URL(command string) string {
+	return e.String() + "/rest/" + escapeCommand(command)
+}
@@ -0,0 +1,196 @@
+package routerosv7_restfull_api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by APIError with errors.Is
+var (
+	ErrNotFound      = errors.New("routeros: not found")      // ErrNotFound matches a missing item or path
+	ErrUnauthorized  = errors.New("routeros: unauthorized")   // ErrUnauthorized matches rejected credentials
+	ErrForbidden     = errors.New("routeros: forbidden")      // ErrForbidden matches a user without the required policy
+	ErrAlreadyExists = errors.New("routeros: already exists") // ErrAlreadyExists matches a duplicate item
+)
+
+/*
+APIError is returned when the Mikrotik Router answers with a non-2xx status code.
+RouterOS describes the failure with a JSON body such as
+{"error": 400, "message": "Bad Request", "detail": "failure: already have such address"}
+which is decoded into Code, Message and Detail when present.
+*/
+type APIError struct {
+	StatusCode int    // HTTP status code of the response
+	Status     string // HTTP status text of the response, e.g. "404 Not Found"
+	Code       int    // Error code from the "error" field of the response body
+	Message    string // Message from the "message" field of the response body
+	Detail     string // Detail from the "detail" field of the response body
+	Method     string // Method of the failed request
+	Path       string // Path of the failed request
+	Body       []byte // Raw response body
+}
+
+// Error returns the HTTP status and the raw response body
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP error: %s, Response body: %s", e.Status, string(e.Body))
+}
+
+/*
+Is reports whether the APIError matches one of the sentinel errors, so that
+errors.Is(err, ErrNotFound) works on errors returned by the client
+*/
+func (e *APIError) Is(target error) bool {
+	detail := strings.ToLower(e.Detail)
+
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || strings.Contains(detail, "no such item")
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrAlreadyExists:
+		return strings.Contains(detail, "already have") || strings.Contains(detail, "already exists")
+	}
+	return false
+}
+
+// newAPIError creates an APIError from the response and its already read body
+func newAPIError(response *http.Response, body []byte) *APIError {
+
+	// Create the APIError from the response
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Body:       body,
+	}
+
+	// Set the method and path of the request if any
+	if response.Request != nil {
+		apiErr.Method = response.Request.Method
+		if response.Request.URL != nil {
+			apiErr.Path = response.Request.URL.Path
+		}
+	}
+
+	// Decode the RouterOS error fields, the body is kept raw if it is not a RouterOS error
+	var fields struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &fields); err == nil {
+		apiErr.Code = fields.Error
+		apiErr.Message = fields.Message
+		apiErr.Detail = fields.Detail
+	}
+
+	// Return the APIError
+	return apiErr
+}
+
+// IsNotFound reports whether err is an APIError for a missing item or path
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError for rejected credentials
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError for a user without the required policy
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsAlreadyExists reports whether err is an APIError for a duplicate item
+func IsAlreadyExists(err error) bool {
+	return errors.Is(err, ErrAlreadyExists)
+}
+
+// TransportError is returned when the request could not be sent or the response could not be received
+type TransportError struct {
+	Method string // Method of the failed request
+	URL    string // URL of the failed request
+	Err    error  // Underlying error
+}
+
+// Error returns the underlying error prefixed with the error kind
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("transport error: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// TLSError is returned when the TLS handshake with the Mikrotik Router fails
+type TLSError struct {
+	Method string // Method of the failed request
+	URL    string // URL of the failed request
+	Err    error  // Underlying error
+}
+
+// Error returns the underlying error prefixed with the error kind
+func (e *TLSError) Error() string {
+	return fmt.Sprintf("TLS error: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TLSError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when the response body of a successful request is not valid JSON
+type DecodeError struct {
+	Method string // Method of the failed request
+	URL    string // URL of the failed request
+	Err    error  // Underlying error
+}
+
+// Error returns the underlying error prefixed with the error kind
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode error: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// isTLSError checks if the error was caused by the TLS handshake or the certificate verification
+func isTLSError(err error) bool {
+	var (
+		recordHeaderErr     tls.RecordHeaderError
+		alertErr            tls.AlertError
+		verificationErr     *tls.CertificateVerificationError
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		certificateErr      x509.CertificateInvalidError
+		mismatchErr         *FingerprintMismatchError
+	)
+
+	return errors.As(err, &recordHeaderErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &verificationErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &certificateErr) ||
+		errors.As(err, &mismatchErr) || strings.Contains(err.Error(), "tls: ")
+}
+
+// wrapSendError wraps an error returned while sending the request into a TLSError or a TransportError
+func wrapSendError(config requestConfig, err error) error {
+
+	// Check if the error was caused by TLS
+	if isTLSError(err) {
+		return &TLSError{Method: config.Method, URL: config.URL, Err: err}
+	}
+
+	// Return a TransportError otherwise
+	return &TransportError{Method: config.Method, URL: config.URL, Err: err}
+}
@@ -0,0 +1,177 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewAPIError tests that the RouterOS error fields, method and path are decoded from the response
+func TestNewAPIError(t *testing.T) {
+	body := []byte(`{"detail":"failure: already have such address","error":400,"message":"Bad Request"}`)
+	request := httptest.NewRequest(http.MethodPut, "http://router/rest/ip/address", nil)
+	response := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Status:     "400 Bad Request",
+		Request:    request,
+	}
+
+	apiErr := newAPIError(response, body)
+
+	assert.Equal(t, &APIError{
+		StatusCode: http.StatusBadRequest,
+		Status:     "400 Bad Request",
+		Code:       400,
+		Message:    "Bad Request",
+		Detail:     "failure: already have such address",
+		Method:     http.MethodPut,
+		Path:       "/rest/ip/address",
+		Body:       body,
+	}, apiErr)
+	assert.Equal(t, "HTTP error: 400 Bad Request, Response body: "+string(body), apiErr.Error())
+}
+
+// TestNewAPIError_NonJSONBody tests that a body which is not a RouterOS error is kept raw
+func TestNewAPIError_NonJSONBody(t *testing.T) {
+	response := &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}
+
+	apiErr := newAPIError(response, []byte("<html>bad gateway</html>"))
+
+	assert.Equal(t, 0, apiErr.Code)
+	assert.Empty(t, apiErr.Message)
+	assert.Equal(t, "<html>bad gateway</html>", string(apiErr.Body))
+}
+
+// TestAPIError_Is tests the sentinel helpers against various RouterOS errors
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name          string    // Test case name
+		err           *APIError // Error under test
+		notFound      bool      // Expected IsNotFound result
+		unauthorized  bool      // Expected IsUnauthorized result
+		forbidden     bool      // Expected IsForbidden result
+		alreadyExists bool      // Expected IsAlreadyExists result
+	}{
+		{"Not found status", &APIError{StatusCode: 404, Message: "Not Found"}, true, false, false, false},
+		{"No such item", &APIError{StatusCode: 400, Detail: "no such item"}, true, false, false, false},
+		{"Unauthorized", &APIError{StatusCode: 401, Message: "Unauthorized"}, false, true, false, false},
+		{"Forbidden", &APIError{StatusCode: 403, Message: "Forbidden"}, false, false, true, false},
+		{"Already have", &APIError{StatusCode: 400, Detail: "failure: already have such address"},
+			false, false, false, true},
+		{"Already exists", &APIError{StatusCode: 400, Detail: "failure: entry already exists"},
+			false, false, false, true},
+		{"Other", &APIError{StatusCode: 500, Detail: "failure: something else"}, false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Wrap the error to check that the helpers see through wrapping
+			err := errors.Join(errors.New("context"), tt.err)
+
+			assert.Equal(t, tt.notFound, IsNotFound(err))
+			assert.Equal(t, tt.unauthorized, IsUnauthorized(err))
+			assert.Equal(t, tt.forbidden, IsForbidden(err))
+			assert.Equal(t, tt.alreadyExists, IsAlreadyExists(err))
+		})
+	}
+}
+
+// TestClient_APIError tests that the client returns an *APIError usable with errors.As
+func TestClient_APIError(t *testing.T) {
+	server := setupMockServer(http.StatusNotFound, `{"error":404,"message":"Not Found"}`)
+	defer server.Close()
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address/*99")
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "Not Found", apiErr.Message)
+	assert.Equal(t, MethodGet, apiErr.Method)
+	assert.Equal(t, "/rest/ip/address/*99", apiErr.Path)
+	assert.True(t, IsNotFound(err))
+}
+
+// TestClient_TransportError tests that a failure to connect is returned as a *TransportError
+func TestClient_TransportError(t *testing.T) {
+	server := setupMockServer(http.StatusOK, `{}`)
+	host := serverHost(server)
+	server.Close()
+
+	client, err := NewClient(host)
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+
+	var transportErr *TransportError
+	require.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, MethodGet, transportErr.Method)
+	assert.Contains(t, transportErr.URL, "/rest/ip/address")
+}
+
+// TestClient_TransportError_Canceled tests that a canceled context is still visible through the TransportError
+func TestClient_TransportError_Canceled(t *testing.T) {
+	server := setupMockServer(http.StatusOK, `{}`)
+	defer server.Close()
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = client.Print(ctx, "ip/address")
+
+	var transportErr *TransportError
+	assert.True(t, errors.As(err, &transportErr))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+// TestClient_TLSError tests that a certificate verification failure is returned as a *TLSError
+func TestClient_TLSError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+
+	var tlsErr *TLSError
+	require.True(t, errors.As(err, &tlsErr))
+	assert.Equal(t, MethodGet, tlsErr.Method)
+}
+
+// TestClient_DecodeError tests that an invalid JSON body is returned as a *DecodeError
+func TestClient_DecodeError(t *testing.T) {
+	server := setupMockServer(http.StatusOK, `not json`)
+	defer server.Close()
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+
+	var decodeErr *DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	assert.True(t, strings.HasSuffix(decodeErr.URL, "/rest/ip/address"))
+	assert.False(t, errors.Is(err, io.EOF))
+}
+
+// TestIsTLSError tests the isTLSError function with TLS and non-TLS errors
+func TestIsTLSError(t *testing.T) {
+	assert.True(t, isTLSError(errors.New("remote error: tls: handshake failure")))
+	assert.False(t, isTLSError(errors.New("dial tcp: connection refused")))
+}
@@ -105,12 +105,19 @@ func main() {
 		return
 	}
 
-	// Create payload variable as []byte with the desired payload data
-	payload := fmt.Sprintf(`{"address": "%s","interface": "%s"}`, payloadIpAddr, payloadInterface)
+	// Create payload variable as []byte with the desired payload data, values are escaped by EncodePayload
+	payload, err := routerosv7_restfull_api.EncodePayload(map[string]interface{}{
+		"address":   payloadIpAddr,
+		"interface": payloadInterface,
+	})
+	if err != nil {
+		fmt.Println("Failed to encode payload:", err)
+		return
+	}
 
 	// Add address with addAddress function and get the response data as map[string]interface{} if there is no error
 	//and print the response data to the console as JSON string
-	response, err := putAddress(context.Background(), routerIP, username, password, "ip/address", []byte(payload))
+	response, err := putAddress(context.Background(), routerIP, username, password, "ip/address", payload)
 	if err != nil {
 		fmt.Println("Failed to add address:", err)
 		return
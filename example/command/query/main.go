@@ -70,10 +70,18 @@ func (r *RouterOSDataRetriever) GetData(ctx context.Context) (interface{}, error
 
 	/*
 		Create bytes variable as []byte with the desired payload data
-		_proplist is used to specify the properties to be returned
-		query is used to specify the query to be executed on the command
+		Proplist is used to specify the properties to be returned
+		Where is used to specify the query to be executed on the command
 	*/
-	payload := []byte(`{"_proplist": ["address","interface"], ".query": ["network=192.168.99.0","#"]}`)
+	payload, err := routerosv7_restfull_api.NewQuery().
+		Where("network", "192.168.99.0").
+		Proplist("address", "interface").
+		Payload()
+
+	// Check if the query is invalid
+	if err != nil {
+		return nil, err
+	}
 
 	// Create a Command using the constructor
 	data, err := routerosv7_restfull_api.Run(ctx, routerIP, username, password, cmd, payload)
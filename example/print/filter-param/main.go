@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/sumitroajiprabowo/routerosv7-restfull-api"
-	"strings"
 )
 
 // Create constants for the default values for this example application
@@ -64,16 +63,16 @@ func authenticate(routerIP, username, password string) error {
 // GetData function to retrieve data from RouterOS device using the config values from the RouterOSDataRetriever
 func (r *RouterOSDataRetriever) GetData(ctx context.Context) (interface{}, error) {
 
-	// Create queryParams variable as slice of string
-	var queryParams []string
+	// Create path variable with the command
+	path := routerosv7_restfull_api.NewPath(r.config.Command)
 
-	// Loop through the params and append the key and value to the queryParams slice
+	// Loop through the params and add the key and value as escaped filter parameters
 	for key, value := range r.config.Params {
-		queryParams = append(queryParams, fmt.Sprintf("%s=%s", key, value))
+		path.Filter(key, value)
 	}
 
-	// Create command variable with the command and queryParams
-	command := fmt.Sprintf(r.config.Command, strings.Join(queryParams, "&"))
+	// Create command variable with the command and filter parameters
+	command := path.String()
 
 	// Calling Print function
 	data, err := routerosv7_restfull_api.Print(ctx, r.config.Host, r.config.Username,
@@ -137,7 +136,7 @@ func main() {
 	}
 
 	// Create config variable with the default values and params
-	config := NewAppConfig(routerIP, username, password, "ip/address?%s", params)
+	config := NewAppConfig(routerIP, username, password, "ip/address", params)
 
 	// Authenticate to the router using the config values from the AppConfig instance
 	err := authenticate(routerIP, username, password)
@@ -58,10 +58,9 @@ func main() {
 		return
 	}
 
-	// Perform the DELETE operation
-	command := fmt.Sprintf("ip/address/%s", getAddressID(context.Background(), routerIP, username, password,
-		"ip/address", "address",
-		payloadIPAddress))
+	// Perform the DELETE operation, the id is escaped by NewPath
+	command := routerosv7_restfull_api.NewPath("ip/address").ID(getAddressID(context.Background(), routerIP,
+		username, password, "ip/address", "address", payloadIPAddress)).String()
 
 	// Check if there is an error and print the error message to the console
 	if response, _ := deleteData(context.Background(), routerIP, username, password, command); response != nil {
@@ -129,17 +129,20 @@ func main() {
 		return
 	}
 
-	// Perform the PATCH operation
-	command := fmt.Sprintf("ip/address/%s", getAddressID(context.Background(), routerIP, username, password,
-		"ip/address", "address",
-		paramAddress))
+	// Perform the PATCH operation, the id is escaped by NewPath
+	command := routerosv7_restfull_api.NewPath("ip/address").ID(getAddressID(context.Background(), routerIP,
+		username, password, "ip/address", "address", paramAddress)).String()
 
-	// Create payload variable as []byte with the desired payload data
-	payload := fmt.Sprintf(`{"comment": "%s"}`, payloadComment)
+	// Create payload variable as []byte with the desired payload data, values are escaped by EncodePatchPayload
+	payload, err := routerosv7_restfull_api.EncodePatchPayload(map[string]interface{}{"comment": payloadComment})
+	if err != nil {
+		fmt.Println("Failed to encode payload:", err)
+		return
+	}
 
 	// Patch the data
 	if response, err := patchData(context.Background(), routerIP, username, password, command,
-		[]byte(payload)); err != nil {
+		payload); err != nil {
 		fmt.Println("Failed to patch data:", err)
 		return
 	} else {
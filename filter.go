@@ -0,0 +1,316 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+/*
+FilterSyntaxError is returned by ParseFilter when the filter expression is invalid.
+Offset is the byte offset of the offending token in the expression.
+*/
+type FilterSyntaxError struct {
+	Expr   string // Filter expression
+	Offset int    // Byte offset of the offending token
+	Msg    string // Description of the error
+}
+
+// Error returns the byte offset of the error followed by its description, e.g. "filter: offset 4: ..."
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("filter: offset %d: %s", e.Offset, e.Msg)
+}
+
+// filterTokenKind is the kind of a token of a filter expression
+type filterTokenKind int
+
+const (
+	filterEOF    filterTokenKind = iota // filterEOF is the end of the expression
+	filterWord                          // filterWord is a property name or a bare value
+	filterString                        // filterString is a quoted value
+	filterOp                            // filterOp is a comparison operator: = != < > or the unsupported ~
+	filterAnd                           // filterAnd is "and" or "&&"
+	filterOr                            // filterOr is "or" or "||"
+	filterNot                           // filterNot is "not" or "!"
+	filterLParen                        // filterLParen is "("
+	filterRParen                        // filterRParen is ")"
+)
+
+// filterToken is a token of a filter expression
+type filterToken struct {
+	kind   filterTokenKind // Kind of the token
+	text   string          // Text of the token, unquoted for strings
+	offset int             // Byte offset of the token in the expression
+}
+
+// describe returns a description of the token for error messages
+func (t filterToken) describe() string {
+	if t.kind == filterEOF {
+		return "end of filter"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// filterDelimiters are the characters ending a bare word
+const filterDelimiters = " \t\r\n()\"=!<>~&|"
+
+// lexFilter splits the filter expression into tokens
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{filterLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{filterRParen, ")", i})
+			i++
+		case c == '!' && strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, filterToken{filterOp, "!=", i})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, filterToken{filterNot, "!", i})
+			i++
+		case c == '=' || c == '<' || c == '>' || c == '~':
+			tokens = append(tokens, filterToken{filterOp, string(c), i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, filterToken{filterAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, filterToken{filterOr, "||", i})
+			i += 2
+		case c == '&' || c == '|':
+			return nil, &FilterSyntaxError{Expr: expr, Offset: i, Msg: fmt.Sprintf("unexpected %q", c)}
+		case c == '"':
+			text, end, ok := unquoteFilterString(expr, i)
+			if !ok {
+				return nil, &FilterSyntaxError{Expr: expr, Offset: i, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{filterString, text, i})
+			i = end
+		default:
+			end := i
+			for end < len(expr) && !strings.ContainsRune(filterDelimiters, rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, filterWordToken(expr[i:end], i))
+			i = end
+		}
+	}
+
+	return append(tokens, filterToken{filterEOF, "", len(expr)}), nil
+}
+
+// filterWordToken returns a keyword token for "and", "or" and "not", or a word token otherwise
+func filterWordToken(word string, offset int) filterToken {
+	switch strings.ToLower(word) {
+	case "and":
+		return filterToken{filterAnd, word, offset}
+	case "or":
+		return filterToken{filterOr, word, offset}
+	case "not":
+		return filterToken{filterNot, word, offset}
+	}
+	return filterToken{filterWord, word, offset}
+}
+
+/*
+unquoteFilterString reads the quoted string starting at start, returning its text and the offset after
+the closing quote. Only \" and \\ are unescaped, other backslashes are kept so that regular expressions
+such as "^10\." can be written as is.
+*/
+func unquoteFilterString(expr string, start int) (string, int, bool) {
+	var builder strings.Builder
+
+	for i := start + 1; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '"':
+			return builder.String(), i + 1, true
+		case c == '\\' && i+1 < len(expr) && (expr[i+1] == '"' || expr[i+1] == '\\'):
+			builder.WriteByte(expr[i+1])
+			i++
+		default:
+			builder.WriteByte(c)
+		}
+	}
+
+	return "", 0, false
+}
+
+// filterParser is a recursive descent parser compiling a filter expression into a Query
+type filterParser struct {
+	expr   string        // Filter expression
+	tokens []filterToken // Tokens of the expression
+	pos    int           // Index of the current token
+	query  *Query        // Query being compiled
+}
+
+/*
+ParseFilter compiles a human-readable filter expression into the .query stack of a print command.
+Conditions are written as property=value, property!=value, property<value, property>value, or a bare property
+name matching items that have the property. They are combined with and, or, not (or &&, ||, !) and parentheses,
+"and" binding tighter than "or". Values containing spaces or operators must be quoted. RouterOS queries cannot
+match regular expressions, so property~"regex" is rejected.
+example:
+
+	ParseFilter(`interface="ether1" and (disabled=no or dynamic=yes)`)
+*/
+func ParseFilter(expr string) (*Query, error) {
+
+	// Split the expression into tokens
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the expression
+	parser := &filterParser{expr: expr, tokens: tokens, query: NewQuery()}
+	if parser.peek().kind == filterEOF {
+		return nil, parser.errorf(parser.peek(), "empty filter")
+	}
+	if err := parser.parseOr(); err != nil {
+		return nil, err
+	}
+
+	// Check that the whole expression was parsed
+	if token := parser.peek(); token.kind != filterEOF {
+		return nil, parser.errorf(token, "unexpected %s, expected \"and\", \"or\" or end of filter", token.describe())
+	}
+
+	return parser.query, nil
+}
+
+// peek returns the current token
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances to the next one
+func (p *filterParser) next() filterToken {
+	token := p.tokens[p.pos]
+	if token.kind != filterEOF {
+		p.pos++
+	}
+	return token
+}
+
+// errorf returns a FilterSyntaxError at the offset of the token
+func (p *filterParser) errorf(token filterToken, format string, args ...interface{}) error {
+	return &FilterSyntaxError{Expr: p.expr, Offset: token.offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses conditions separated by "or"
+func (p *filterParser) parseOr() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+	for p.peek().kind == filterOr {
+		p.next()
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+		p.query.Op("|")
+	}
+	return nil
+}
+
+// parseAnd parses conditions separated by "and"
+func (p *filterParser) parseAnd() error {
+	if err := p.parseUnary(); err != nil {
+		return err
+	}
+	for p.peek().kind == filterAnd {
+		p.next()
+		if err := p.parseUnary(); err != nil {
+			return err
+		}
+		p.query.Op("&")
+	}
+	return nil
+}
+
+// parseUnary parses a condition optionally negated with "not"
+func (p *filterParser) parseUnary() error {
+	if p.peek().kind == filterNot {
+		p.next()
+		if err := p.parseUnary(); err != nil {
+			return err
+		}
+		p.query.Op("!")
+		return nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a parenthesized expression or a single condition
+func (p *filterParser) parsePrimary() error {
+	token := p.next()
+
+	switch token.kind {
+	case filterLParen:
+		if err := p.parseOr(); err != nil {
+			return err
+		}
+		if closing := p.next(); closing.kind != filterRParen {
+			return p.errorf(closing, "unexpected %s, expected \")\" to close \"(\" at offset %d",
+				closing.describe(), token.offset)
+		}
+		return nil
+	case filterWord:
+		return p.parseCondition(token)
+	}
+
+	return p.errorf(token, "unexpected %s, expected property name, \"not\" or \"(\"", token.describe())
+}
+
+// parseCondition parses a comparison, or a bare property name, starting with the name token
+func (p *filterParser) parseCondition(name filterToken) error {
+
+	// Validate the property name
+	if !queryNamePattern.MatchString(name.text) {
+		return p.errorf(name, "invalid property name %q", name.text)
+	}
+
+	// A bare property name matches items that have the property
+	if p.peek().kind != filterOp {
+		p.query.Has(name.text)
+		return nil
+	}
+
+	// Read the operator and the value
+	operator := p.next()
+	if operator.text == "~" {
+		return p.errorf(operator, "unsupported operator \"~\", RouterOS queries cannot match regular expressions")
+	}
+	value := p.next()
+	if value.kind != filterWord && value.kind != filterString {
+		return p.errorf(value, "unexpected %s, expected value after %q", value.describe(), operator.text)
+	}
+
+	switch operator.text {
+	case "=":
+		p.query.Where(name.text, value.text)
+	case "!=":
+		p.query.Where(name.text, value.text).Op("!")
+	case "<":
+		p.query.Less(name.text, value.text)
+	case ">":
+		p.query.Greater(name.text, value.text)
+	}
+	return nil
+}
+
+// RunFilter compiles the filter expression with ParseFilter and runs it on a print command, returning the result and error
+func (c *Client) RunFilter(ctx context.Context, command, filter string) (interface{}, error) {
+	query, err := ParseFilter(filter)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+	return c.RunQuery(ctx, command, query)
+}
@@ -0,0 +1,126 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFilter tests the .query stack compiled from valid filter expressions
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string   // Test case name
+		filter   string   // Filter expression
+		expected []string // Expected .query words
+	}{
+		{"Equality", `network=192.168.99.0`, []string{"network=192.168.99.0"}},
+		{"Has", `comment`, []string{"comment"}},
+		{"Not equal", `disabled!=yes`, []string{"disabled=yes", "#!"}},
+		{"Less and greater", `mtu<1500 && mtu>1000`, []string{"<mtu=1500", ">mtu=1000", "#&"}},
+		{"Or", `disabled=no or dynamic=yes`, []string{"disabled=no", "dynamic=yes", "#|"}},
+		{"And binds tighter than or", `a=1 or b=2 and c=3`, []string{"a=1", "b=2", "c=3", "#&", "#|"}},
+		{"Parentheses", `(a=1 or b=2) and c=3`, []string{"a=1", "b=2", "#|", "c=3", "#&"}},
+		{"Not", `not comment`, []string{"comment", "#!"}},
+		{"Bang", `!(a=1 || b=2)`, []string{"a=1", "b=2", "#|", "#!"}},
+		{"Double not", `not not comment`, []string{"comment", "#!", "#!"}},
+		{"Keywords are case insensitive", `a=1 AND b=2 Or NOT c`, []string{"a=1", "b=2", "#&", "c", "#!", "#|"}},
+		{"Quoted value", `comment="office uplink"`, []string{"comment=office uplink"}},
+		{"Escaped quote", `comment="say \"hi\""`, []string{`comment=say "hi"`}},
+		{"Id and prefix", `.id=*1A or address=10.0.0.1/24`, []string{".id=*1A", "address=10.0.0.1/24", "#|"}},
+		{
+			"Example from the docs",
+			`interface="ether1" and (disabled=no or dynamic=yes)`,
+			[]string{"interface=ether1", "disabled=no", "dynamic=yes", "#|", "#&"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query.Words())
+
+			// The compiled query always leaves a single value on the stack
+			depth, err := queryStackDepth(query.Words())
+			require.NoError(t, err)
+			assert.Equal(t, 1, depth)
+		})
+	}
+}
+
+// TestParseFilter_Errors tests the error positions of invalid filter expressions
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name    string // Test case name
+		filter  string // Filter expression
+		offset  int    // Expected offset
+		message string // Expected error message
+	}{
+		{"Empty", ``, 0, `filter: offset 0: empty filter`},
+		{"Blank", `   `, 3, `filter: offset 3: empty filter`},
+		{"Missing value", `address=`, 8, `filter: offset 8: unexpected end of filter, expected value after "="`},
+		{"Missing operand", `a=1 and`, 7,
+			`filter: offset 7: unexpected end of filter, expected property name, "not" or "("`},
+		{"Unclosed parenthesis", `(a=1 or b=2`, 11,
+			`filter: offset 11: unexpected end of filter, expected ")" to close "(" at offset 0`},
+		{"Unclosed nested parenthesis", `a=1 and (b=2 or (c=3)`, 21,
+			`filter: offset 21: unexpected end of filter, expected ")" to close "(" at offset 8`},
+		{"Extra parenthesis", `a=1)`, 3, `filter: offset 3: unexpected ")", expected "and", "or" or end of filter`},
+		{"Missing operator", `a=1 b=2`, 4, `filter: offset 4: unexpected "b", expected "and", "or" or end of filter`},
+		{"Unterminated string", `comment="abc`, 8, `filter: offset 8: unterminated string`},
+		{"Single ampersand", `a=1 & b=2`, 4, `filter: offset 4: unexpected '&'`},
+		{"Invalid name", `Address=1`, 0, `filter: offset 0: invalid property name "Address"`},
+		{"Operator first", `=1`, 0, `filter: offset 0: unexpected "=", expected property name, "not" or "("`},
+		{"Regex", `address~"^10\."`, 7,
+			`filter: offset 7: unsupported operator "~", RouterOS queries cannot match regular expressions`},
+		{"Value is operator", `a==1`, 2, `filter: offset 2: unexpected "=", expected value after "="`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter)
+
+			var syntaxErr *FilterSyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "error: %v", err)
+			assert.Equal(t, tt.offset, syntaxErr.Offset)
+			assert.Equal(t, tt.filter, syntaxErr.Expr)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+// TestParseFilter_SameAsBuilder tests that the parser and the builder compile the same payload
+func TestParseFilter_SameAsBuilder(t *testing.T) {
+	parsed, err := ParseFilter(`network=192.168.99.0 or network=10.0.0.0`)
+	require.NoError(t, err)
+
+	built := NewQuery().Where("network", "192.168.99.0").Or("network", "10.0.0.0")
+
+	parsedPayload, err := parsed.Payload()
+	require.NoError(t, err)
+	builtPayload, err := built.Payload()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(builtPayload), string(parsedPayload))
+}
+
+// TestClient_RunFilter tests that RunFilter posts the compiled filter
+func TestClient_RunFilter(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	_, err = client.RunFilter(context.Background(), "ip/address/print", `interface=ether1 and not disabled=yes`)
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 1)
+	assert.JSONEq(t, `{".query":["interface=ether1","disabled=yes","#!","#&"]}`, (*requests)[0].Body)
+
+	// An invalid filter is rejected before any request is sent
+	_, err = client.RunFilter(context.Background(), "ip/address/print", `interface=`)
+	assert.Error(t, err)
+	assert.Len(t, *requests, 1)
+}
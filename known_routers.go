@@ -0,0 +1,276 @@
+package routerosv7_restfull_api
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FingerprintMismatchError is returned when a router presents a certificate other than the one pinned for it
+type FingerprintMismatchError struct {
+	Address  string // Address of the router, host:port
+	Expected string // Fingerprint pinned for the router
+	Actual   string // Fingerprint of the certificate presented by the router
+}
+
+// Error returns the address of the router and both fingerprints
+func (e *FingerprintMismatchError) Error() string {
+	return fmt.Sprintf("certificate fingerprint mismatch for %s: expected %s, got %s", e.Address, e.Expected,
+		e.Actual)
+}
+
+// KnownRouter is an entry of a KnownRouters store
+type KnownRouter struct {
+	Address     string // Address of the router, host:port
+	Fingerprint string // SHA-256 fingerprint of the router's certificate, lower case hex
+}
+
+/*
+KnownRouters is a store of router certificate fingerprints kept in a local file, like the known_hosts file of SSH.
+With WithKnownRouters, the fingerprint of a router's certificate is recorded on first contact (trust on first
+use) and later connections presenting another certificate fail with a FingerprintMismatchError.
+The file holds one "host:port fingerprint" entry per line, blank lines and lines starting with "#" are ignored.
+A KnownRouters is safe for concurrent use by multiple clients.
+*/
+type KnownRouters struct {
+	path    string            // Path of the file
+	mu      sync.Mutex        // Mutex protecting the entries and the file
+	entries map[string]string // Fingerprints by address
+}
+
+/*
+OpenKnownRouters loads the known-routers file at path.
+A missing file is treated as empty and is created when the first entry is recorded.
+*/
+func OpenKnownRouters(path string) (*KnownRouters, error) {
+	store := &KnownRouters{path: path, entries: make(map[string]string)}
+
+	// Read the file
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil // Return an empty store
+	}
+	if err != nil {
+		return nil, fmt.Errorf("OpenKnownRouters: %w", err)
+	}
+
+	// Parse the entries
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("OpenKnownRouters: %s:%d: expected \"host:port fingerprint\"", path, line)
+		}
+		address, err := knownRouterAddress(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("OpenKnownRouters: %s:%d: %w", path, line, err)
+		}
+		fingerprint, err := normalizeFingerprint(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("OpenKnownRouters: %s:%d: %w", path, line, err)
+		}
+		store.entries[address] = fingerprint
+	}
+
+	return store, nil
+}
+
+// List returns the entries of the store sorted by address
+func (k *KnownRouters) List() []KnownRouter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	routers := make([]KnownRouter, 0, len(k.entries))
+	for address, fingerprint := range k.entries {
+		routers = append(routers, KnownRouter{Address: address, Fingerprint: fingerprint})
+	}
+	sort.Slice(routers, func(i, j int) bool { return routers[i].Address < routers[j].Address })
+	return routers
+}
+
+/*
+Pin records the fingerprint for the router and saves the file, replacing any previous entry.
+The address may omit the port, which defaults to 443, and the fingerprint may be upper case or colon separated.
+*/
+func (k *KnownRouters) Pin(address, fingerprint string) error {
+	address, err := knownRouterAddress(address)
+	if err != nil {
+		return fmt.Errorf("Pin: %w", err)
+	}
+	fingerprint, err = normalizeFingerprint(fingerprint)
+	if err != nil {
+		return fmt.Errorf("Pin: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.entries[address] = fingerprint
+	return k.save()
+}
+
+// Revoke removes the entry of the router and saves the file, the next connection records a new fingerprint
+func (k *KnownRouters) Revoke(address string) error {
+	address, err := knownRouterAddress(address)
+	if err != nil {
+		return fmt.Errorf("Revoke: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// Check if the router is known
+	if _, ok := k.entries[address]; !ok {
+		return nil
+	}
+
+	delete(k.entries, address)
+	return k.save()
+}
+
+/*
+Verify checks the certificate of the router against its entry.
+On first contact the fingerprint is recorded and saved, on later contacts a different fingerprint
+returns a FingerprintMismatchError.
+*/
+func (k *KnownRouters) Verify(address string, certificate *x509.Certificate) error {
+	address, err := knownRouterAddress(address)
+	if err != nil {
+		return err
+	}
+	actual := CertificateFingerprint(certificate)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// Check if the router is known
+	expected, ok := k.entries[address]
+	if !ok {
+		k.entries[address] = actual // Trust on first use
+		return k.save()
+	}
+
+	// Check if the certificate is the pinned one
+	if expected != actual {
+		return &FingerprintMismatchError{Address: address, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// save writes the entries to a temporary file and renames it over the file, the mutex must be held
+func (k *KnownRouters) save() error {
+	var buffer bytes.Buffer
+	buffer.WriteString("# Known RouterOS routers: host:port SHA-256 certificate fingerprint\n")
+	for _, address := range sortedKeys(k.entries) {
+		fmt.Fprintf(&buffer, "%s %s\n", address, k.entries[address])
+	}
+
+	// Write the temporary file next to the file so that the rename is atomic
+	temp, err := os.CreateTemp(filepath.Dir(k.path), filepath.Base(k.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("KnownRouters: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(buffer.Bytes()); err != nil {
+		_ = temp.Close()
+		return fmt.Errorf("KnownRouters: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("KnownRouters: %w", err)
+	}
+	if err := os.Rename(temp.Name(), k.path); err != nil {
+		return fmt.Errorf("KnownRouters: %w", err)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the map in ascending order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// knownRouterAddress normalizes the address of a router to host:port, the port defaulting to 443
+func knownRouterAddress(address string) (string, error) {
+	endpoint, err := ParseEndpoint(address)
+	if err != nil {
+		return "", err
+	}
+	return endpoint.WithScheme(httpsProtocol).Address(), nil
+}
+
+// normalizeFingerprint converts a SHA-256 fingerprint to lower case hex without separators
+func normalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+	if decoded, err := hex.DecodeString(normalized); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q", fingerprint)
+	}
+	return normalized, nil
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of the certificate as lower case hex
+func CertificateFingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+/*
+WithKnownRouters pins the router's certificate in the known-routers store instead of verifying it against
+certificate authorities, so that self-signed certificates are accepted on first contact and rejected when
+they change afterwards.
+*/
+func WithKnownRouters(store *KnownRouters) Option {
+	return func(o *clientOptions) error {
+		// Check if the store is nil
+		if store == nil {
+			return errors.New("WithKnownRouters: nil store")
+		}
+		o.tls.knownRouters = store // Set the store
+		return nil
+	}
+}
+
+/*
+pinCertificate configures the TLS configuration to verify the certificate of the address with the store.
+A VerifyConnection set by the caller with WithTLSConfig still runs after the fingerprint check.
+*/
+func pinCertificate(config *tls.Config, store *KnownRouters, address string) {
+	verifyConnection := config.VerifyConnection // Check set by the caller, if any
+
+	// The chain is not verified against certificate authorities, the fingerprint is checked instead
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("tls: router presented no certificate")
+		}
+		if err := store.Verify(address, state.PeerCertificates[0]); err != nil {
+			return err
+		}
+
+		// Check if the caller set its own check
+		if verifyConnection != nil {
+			return verifyConnection(state)
+		}
+		return nil
+	}
+}
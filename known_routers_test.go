@@ -0,0 +1,157 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFingerprint is a valid SHA-256 fingerprint used by the tests
+const testFingerprint = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// TestKnownRouters_TrustOnFirstUse tests that the first certificate is recorded and later mismatches are rejected
+func TestKnownRouters_TrustOnFirstUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_routers")
+	store, err := OpenKnownRouters(path)
+	require.NoError(t, err)
+	assert.Empty(t, store.List())
+
+	// The first contact records the fingerprint
+	first := setupTLSServer(t, nil)
+	client, err := NewClient(first.URL, WithKnownRouters(store))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	address := strings.TrimPrefix(first.URL, "https://")
+	assert.Equal(t, []KnownRouter{{Address: address, Fingerprint: CertificateFingerprint(first.Certificate())}},
+		store.List())
+
+	// The entry is saved to the file
+	reopened, err := OpenKnownRouters(path)
+	require.NoError(t, err)
+	assert.Equal(t, store.List(), reopened.List())
+
+	// A later contact with the same certificate succeeds
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+
+	// Another certificate on the same address is rejected
+	require.NoError(t, reopened.Pin(address, testFingerprint))
+	client, err = NewClient(first.URL, WithKnownRouters(reopened))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+
+	var mismatchErr *FingerprintMismatchError
+	require.True(t, errors.As(err, &mismatchErr), "error: %v", err)
+	assert.Equal(t, address, mismatchErr.Address)
+	assert.Equal(t, testFingerprint, mismatchErr.Expected)
+	assert.Equal(t, CertificateFingerprint(first.Certificate()), mismatchErr.Actual)
+
+	var tlsErr *TLSError
+	assert.True(t, errors.As(err, &tlsErr))
+
+	// Revoking the entry trusts the certificate again on the next contact
+	require.NoError(t, reopened.Revoke(address))
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+}
+
+// TestKnownRouters_CallerVerifyConnection tests that the VerifyConnection of WithTLSConfig runs after the pin check
+func TestKnownRouters_CallerVerifyConnection(t *testing.T) {
+	server := setupTLSServer(t, nil)
+	store, err := OpenKnownRouters(filepath.Join(t.TempDir(), "known_routers"))
+	require.NoError(t, err)
+
+	calls := 0
+	callerErr := errors.New("caller check failed")
+	client, err := NewClient(server.URL, WithKnownRouters(store), WithTLSConfig(&tls.Config{
+		VerifyConnection: func(tls.ConnectionState) error {
+			calls++
+			return callerErr
+		},
+	}))
+	require.NoError(t, err)
+
+	// The certificate is pinned, then rejected by the check of the caller
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.ErrorIs(t, err, callerErr)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, store.List(), 1)
+
+	// The check of the caller does not run when the pin check fails
+	require.NoError(t, store.Pin(strings.TrimPrefix(server.URL, "https://"), testFingerprint))
+	_, err = client.Print(context.Background(), "ip/address")
+	var mismatchErr *FingerprintMismatchError
+	assert.True(t, errors.As(err, &mismatchErr), "error: %v", err)
+	assert.Equal(t, 1, calls)
+}
+
+// TestKnownRouters_PinAndRevoke tests the normalization of pinned entries and the file written
+func TestKnownRouters_PinAndRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_routers")
+	store, err := OpenKnownRouters(path)
+	require.NoError(t, err)
+
+	// The port defaults to 443 and the fingerprint is normalized
+	colonFingerprint := strings.ToUpper(testFingerprint[:2] + ":" + testFingerprint[2:])
+	require.NoError(t, store.Pin("Router.LAN", colonFingerprint))
+	require.NoError(t, store.Pin("[fe80::1]:8443", testFingerprint))
+
+	assert.Equal(t, []KnownRouter{
+		{Address: "[fe80::1]:8443", Fingerprint: testFingerprint},
+		{Address: "router.lan:443", Fingerprint: testFingerprint},
+	}, store.List())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "router.lan:443 "+testFingerprint+"\n")
+
+	// Revoking an entry removes it, revoking an unknown router is a no-op
+	require.NoError(t, store.Revoke("router.lan"))
+	require.NoError(t, store.Revoke("unknown.lan"))
+	assert.Equal(t, []KnownRouter{{Address: "[fe80::1]:8443", Fingerprint: testFingerprint}}, store.List())
+
+	// Invalid entries are rejected
+	assert.Error(t, store.Pin("router.lan", "abc"))
+	assert.Error(t, store.Pin("ftp://router.lan", testFingerprint))
+	assert.Error(t, store.Revoke(""))
+}
+
+// TestOpenKnownRouters_File tests the parsing of the known-routers file
+func TestOpenKnownRouters_File(t *testing.T) {
+	dir := t.TempDir()
+
+	// Comments and blank lines are ignored
+	path := filepath.Join(dir, "known_routers")
+	content := "# comment\n\nrouter.lan " + testFingerprint + "\n  10.0.0.1:8443   " + testFingerprint + "  \n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	store, err := OpenKnownRouters(path)
+	require.NoError(t, err)
+	assert.Equal(t, []KnownRouter{
+		{Address: "10.0.0.1:8443", Fingerprint: testFingerprint},
+		{Address: "router.lan:443", Fingerprint: testFingerprint},
+	}, store.List())
+
+	// Malformed lines are rejected with their line number
+	for _, content := range []string{"router.lan\n", "router.lan not-a-fingerprint\n", "a b c\n", "ftp://x " + testFingerprint} {
+		path := filepath.Join(dir, "invalid")
+		require.NoError(t, os.WriteFile(path, []byte("# comment\n"+content), 0o600))
+		_, err := OpenKnownRouters(path)
+		assert.ErrorContains(t, err, path+":2:")
+	}
+}
+
+// TestWithKnownRouters_Nil tests that a nil store is rejected
+func TestWithKnownRouters_Nil(t *testing.T) {
+	_, err := NewClient("router.lan", WithKnownRouters(nil))
+	assert.EqualError(t, err, "WithKnownRouters: nil store")
+}
@@ -0,0 +1,236 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Limiter limits the requests sent to a router with a token bucket, refilled at a rate of requests per second up to
+a burst, and a maximum number of requests in flight. Requests waiting for a token or a slot are queued until their
+context ends. A Limiter is safe for concurrent use and may be shared by the clients of the same router with
+WithLimiter, so that the router sees the combined limits.
+*/
+type Limiter struct {
+	rate  float64          // Tokens added per second, zero for no rate limit
+	burst int              // Maximum number of tokens in the bucket
+	now   func() time.Time // Clock, replaced in tests
+
+	mu     sync.Mutex // Mutex protecting the bucket
+	tokens float64    // Tokens in the bucket, negative when requests reserved future tokens
+	last   time.Time  // Time of the last refill of the bucket
+
+	slots  chan struct{} // Semaphore of the requests in flight, nil for no limit
+	queued atomic.Int64  // Number of requests waiting for a token or a slot
+}
+
+/*
+NewLimiter creates a limiter allowing rate requests per second with bursts of burst requests, and at most
+maxInFlight requests in flight. A zero rate or maxInFlight disables the corresponding limit.
+example:
+NewLimiter(5, 10, 2) // 5 requests per second, bursts of 10 requests, 2 concurrent requests
+*/
+func NewLimiter(rate float64, burst, maxInFlight int) (*Limiter, error) {
+
+	// Check the limits
+	switch {
+	case rate < 0:
+		return nil, errors.New("NewLimiter: rate must not be negative")
+	case rate > 0 && burst < 1:
+		return nil, errors.New("NewLimiter: burst must be positive")
+	case maxInFlight < 0:
+		return nil, errors.New("NewLimiter: maxInFlight must not be negative")
+	}
+
+	// Create the limiter with a full bucket
+	limiter := &Limiter{rate: rate, burst: burst, now: time.Now, tokens: float64(burst)}
+	if maxInFlight > 0 {
+		limiter.slots = make(chan struct{}, maxInFlight)
+	}
+	return limiter, nil
+}
+
+// QueueDepth returns the number of requests waiting for a token or a slot
+func (l *Limiter) QueueDepth() int {
+	return int(l.queued.Load())
+}
+
+// InFlight returns the number of requests holding a slot
+func (l *Limiter) InFlight() int {
+	return len(l.slots)
+}
+
+/*
+Acquire waits for a slot and a token, returning the function releasing the slot once the request is done.
+It returns an error wrapping the context error if the context ends first, or at once if the context
+deadline expires before a token is available.
+*/
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	l.queued.Add(1)
+	defer l.queued.Add(-1)
+
+	// Wait for a slot
+	release = func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, fmt.Errorf("limiter: waiting for a slot: %w", ctx.Err())
+		}
+	}
+
+	// Wait for a token
+	if err := l.waitToken(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// waitToken reserves a token from the bucket and waits until it is available
+func (l *Limiter) waitToken(ctx context.Context) error {
+
+	// Check if the rate is limited
+	if l.rate == 0 {
+		return nil
+	}
+
+	wait := l.reserve()
+	if wait == 0 {
+		return nil
+	}
+
+	// Give the token back if the context deadline expires before the token is available
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(l.now().Add(wait)) {
+		l.cancel()
+		return fmt.Errorf("limiter: waiting %s for a token exceeds the context deadline: %w",
+			wait, context.DeadlineExceeded)
+	}
+
+	// Wait for the token or the end of the context
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return fmt.Errorf("limiter: waiting for a token: %w", ctx.Err())
+	}
+}
+
+// reserve refills the bucket and takes a token, returning the time until the token is available
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Refill the bucket for the time elapsed since the last refill
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+
+	// Take a token, possibly from the future
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token that was not used
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+}
+
+// limiterMiddleware sends every attempt of a request once the limiter allows it
+func limiterMiddleware(limiter *Limiter) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			release, err := limiter.Acquire(ctx)
+			if err != nil {
+				return nil, err // Return nil and error
+			}
+			defer release()
+
+			return next.Do(ctx, request)
+		})
+	}
+}
+
+/*
+WithRateLimit limits the client to rate requests per second with bursts of burst requests.
+Every attempt of a retried request counts.
+*/
+func WithRateLimit(rate float64, burst int) Option {
+	return func(o *clientOptions) error {
+		// Check if the rate is positive
+		if rate <= 0 || burst < 1 {
+			return errors.New("WithRateLimit: rate and burst must be positive")
+		}
+		o.rate, o.burst = rate, burst // Set the rate limit
+		return nil
+	}
+}
+
+// WithMaxInFlight limits the number of requests of the client in flight at the same time
+func WithMaxInFlight(n int) Option {
+	return func(o *clientOptions) error {
+		// Check if the number is positive
+		if n < 1 {
+			return errors.New("WithMaxInFlight: number must be positive")
+		}
+		o.maxInFlight = n // Set the number of requests in flight
+		return nil
+	}
+}
+
+// WithLimiter sets a limiter shared with other clients of the same router, instead of WithRateLimit and WithMaxInFlight
+func WithLimiter(limiter *Limiter) Option {
+	return func(o *clientOptions) error {
+		// Check if the limiter is nil
+		if limiter == nil {
+			return errors.New("WithLimiter: nil limiter")
+		}
+		o.limiter = limiter // Set the limiter
+		return nil
+	}
+}
+
+// newClientLimiter returns the limiter of the options, or a limiter for the rate limit and in-flight cap if any
+func newClientLimiter(options clientOptions) (*Limiter, error) {
+
+	// Check if the limits conflict with a shared limiter
+	if options.limiter != nil {
+		if options.rate != 0 || options.maxInFlight != 0 {
+			return nil, errors.New("NewClient: WithLimiter conflicts with WithRateLimit and WithMaxInFlight")
+		}
+		return options.limiter, nil
+	}
+
+	// Check if the client is limited
+	if options.rate == 0 && options.maxInFlight == 0 {
+		return nil, nil
+	}
+	return NewLimiter(options.rate, options.burst, options.maxInFlight)
+}
+
+// QueueDepth returns the number of requests of the limiter waiting for a token or a slot, zero without a limiter
+func (c *Client) QueueDepth() int {
+	if c.limiter == nil {
+		return 0
+	}
+	return c.limiter.QueueDepth()
+}
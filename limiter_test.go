@@ -0,0 +1,203 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBlockingServer creates a server holding every request until release is closed, counting the peak concurrency
+func setupBlockingServer(t *testing.T, release chan struct{}) (*httptest.Server, *atomic.Int64) {
+	var current, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &peak
+}
+
+// TestLimiter_TokenBucket tests the refill of the bucket and the wait for future tokens
+func TestLimiter_TokenBucket(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, err := NewLimiter(2, 2, 0)
+	require.NoError(t, err)
+	limiter.now = func() time.Time { return now }
+
+	// The burst is available at once
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+
+	// The next tokens are reserved in the future
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+	assert.Equal(t, time.Second, limiter.reserve())
+
+	// The bucket refills with time, up to the burst
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+
+	// A cancelled reservation gives the token back
+	limiter.cancel()
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+}
+
+// TestWithMaxInFlight tests that the requests beyond the cap are queued until a slot is released
+func TestWithMaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server, peak := setupBlockingServer(t, release)
+
+	client, err := NewClient(serverHost(server), WithMaxInFlight(2))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Print(context.Background(), "ip/address")
+			assert.NoError(t, err)
+		}()
+	}
+
+	// Two requests are in flight and three are queued
+	assert.Eventually(t, func() bool { return client.QueueDepth() == 3 && peak.Load() == 2 }, time.Second,
+		time.Millisecond)
+	assert.Equal(t, 2, client.limiter.InFlight())
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(2), peak.Load())
+	assert.Equal(t, 0, client.QueueDepth())
+	assert.Equal(t, 0, client.limiter.InFlight())
+}
+
+// TestWithMaxInFlight_ContextDeadline tests that a queued request fails when its context ends
+func TestWithMaxInFlight_ContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := setupBlockingServer(t, release)
+	defer close(release)
+
+	client, err := NewClient(serverHost(server), WithMaxInFlight(1))
+	require.NoError(t, err)
+
+	go func() { _, _ = client.Print(context.Background(), "ip/address") }()
+	require.Eventually(t, func() bool { return client.limiter.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Print(ctx, "ip/address")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, client.QueueDepth())
+}
+
+// TestWithRateLimit tests that the requests beyond the burst are spaced by the rate
+func TestWithRateLimit(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+
+	client, err := NewClient(serverHost(server), WithRateLimit(50, 1))
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.Print(context.Background(), "ip/address")
+		require.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	assert.Len(t, *requests, 3)
+}
+
+// TestWithRateLimit_ContextDeadline tests that a request fails at once when the deadline expires before its token
+func TestWithRateLimit_ContextDeadline(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+
+	client, err := NewClient(serverHost(server), WithRateLimit(0.1, 1))
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.Print(ctx, "ip/address")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Len(t, *requests, 1)
+}
+
+// TestWithLimiter_Shared tests that clients sharing a limiter share its cap
+func TestWithLimiter_Shared(t *testing.T) {
+	release := make(chan struct{})
+	server, peak := setupBlockingServer(t, release)
+
+	limiter, err := NewLimiter(0, 0, 1)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		client, err := NewClient(serverHost(server), WithLimiter(limiter))
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Print(context.Background(), "ip/address")
+			assert.NoError(t, err)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return limiter.QueueDepth() == 2 && peak.Load() == 1 }, time.Second,
+		time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), peak.Load())
+}
+
+// TestLimiterOptions_Invalid tests that invalid limits are rejected
+func TestLimiterOptions_Invalid(t *testing.T) {
+	for _, option := range []Option{
+		WithRateLimit(0, 1),
+		WithRateLimit(1, 0),
+		WithMaxInFlight(0),
+		WithLimiter(nil),
+	} {
+		_, err := NewClient("router.lan", option)
+		assert.Error(t, err)
+	}
+
+	limiter, err := NewLimiter(1, 1, 1)
+	require.NoError(t, err)
+	_, err = NewClient("router.lan", WithLimiter(limiter), WithMaxInFlight(2))
+	assert.Error(t, err)
+
+	_, err = NewLimiter(-1, 1, 0)
+	assert.Error(t, err)
+	_, err = NewLimiter(1, 0, 0)
+	assert.Error(t, err)
+	_, err = NewLimiter(0, 0, -1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// redacted replaces the secrets in the logs
+const redacted = "REDACTED"
+
+// discardHandler is a slog handler dropping every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// discardLogger is the logger of the clients created without WithLogger, the package never logs globally
+var discardLogger = slog.New(discardHandler{})
+
+/*
+WithLogger sets the logger of the client, which logs nothing by default. Requests and responses are logged at the
+Debug level with their method, path, status, latency and size; warnings such as a disabled certificate verification
+are logged at the Warn level. The Authorization header and the password-like fields of the payloads are redacted.
+*/
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) error {
+		// Check if the logger is nil
+		if logger == nil {
+			return errors.New("WithLogger: nil logger")
+		}
+		o.logger = logger // Set the logger
+		return nil
+	}
+}
+
+// loggerOrDiscard returns the logger, or the discarding logger if nil
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return discardLogger
+	}
+	return logger
+}
+
+// sensitiveHeaders are the headers whose values are redacted in the logs
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactedHeader is a header logged with the values of the sensitive headers redacted
+type redactedHeader http.Header
+
+// LogValue returns the header with the sensitive values redacted, it is only called when the record is logged
+func (h redactedHeader) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		for _, sensitive := range sensitiveHeaders {
+			if strings.EqualFold(name, sensitive) {
+				value = redacted
+			}
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// sensitiveFields are the substrings of the payload field names whose values are redacted in the logs
+var sensitiveFields = []string{"password", "passphrase", "secret", "key", "token", "psk"}
+
+// isSensitiveField checks if the payload field name looks like a password, e.g. "password" or "wpa2-pre-shared-key"
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedPayload is a JSON payload logged with the values of the password-like fields redacted
+type redactedPayload []byte
+
+/*
+LogValue returns the payload with the password-like fields redacted at any depth, it is only called when the
+record is logged. A payload that is not valid JSON is redacted entirely.
+*/
+func (p redactedPayload) LogValue() slog.Value {
+
+	// Check if the payload is empty
+	if len(p) == 0 {
+		return slog.StringValue("")
+	}
+
+	// Decode the payload
+	var payload interface{}
+	if err := json.Unmarshal(p, &payload); err != nil {
+		return slog.StringValue(redacted)
+	}
+
+	// Encode the redacted payload
+	encoded, err := json.Marshal(redactValue(payload))
+	if err != nil {
+		return slog.StringValue(redacted)
+	}
+	return slog.StringValue(string(encoded))
+}
+
+// redactValue replaces the values of the password-like fields of the decoded JSON value
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			if isSensitiveField(name) {
+				value[name] = redacted
+			} else {
+				value[name] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,149 @@
+package routerosv7_restfull_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLogRecords decodes the JSON records written by a slog.JSONHandler
+func decodeLogRecords(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// TestWithLogger_RequestAndResponse tests the Debug records of a request and its response
+func TestWithLogger_RequestAndResponse(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := newMockClient(t, http.StatusOK, `{"ret":"*1"}`,
+		WithCredentials("admin", "s3cr3t"), WithLogger(logger))
+
+	payload := []byte(`{"name":"vpn","password":"hunter2","profile":{"secret":"shh"}}`)
+	_, err := client.Add(context.Background(), "ppp/secret", payload)
+	require.NoError(t, err)
+
+	records := decodeLogRecords(t, &output)
+	require.Len(t, records, 2)
+
+	request := records[0]
+	assert.Equal(t, "DEBUG", request["level"])
+	assert.Equal(t, "routeros request", request["msg"])
+	assert.Equal(t, MethodPut, request["method"])
+	assert.Equal(t, "/rest/ppp/secret", request["path"])
+	assert.Equal(t, float64(len(payload)), request["bytes"])
+	assert.Equal(t, redacted, request["header"].(map[string]interface{})["Authorization"])
+	assert.JSONEq(t, `{"name":"vpn","password":"REDACTED","profile":{"secret":"REDACTED"}}`,
+		request["payload"].(string))
+
+	response := records[1]
+	assert.Equal(t, "routeros response", response["msg"])
+	assert.Equal(t, float64(http.StatusOK), response["status"])
+	assert.Equal(t, float64(len(`{"ret":"*1"}`)), response["bytes"])
+	assert.Contains(t, response, "latency")
+
+	// The secrets never reach the logs
+	for _, secret := range []string{"s3cr3t", "hunter2", "shh", base64.StdEncoding.EncodeToString([]byte("admin:s3cr3t"))} {
+		assert.NotContains(t, output.String(), secret)
+	}
+}
+
+// TestWithLogger_ErrorResponse tests the Debug record of an error response
+func TestWithLogger_ErrorResponse(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	body := `{"error":404,"message":"Not Found"}`
+	client := newMockClient(t, http.StatusNotFound, body, WithLogger(logger))
+	_, err := client.Remove(context.Background(), "ip/address/*9")
+	require.Error(t, err)
+
+	records := decodeLogRecords(t, &output)
+	require.Len(t, records, 2)
+	assert.Equal(t, float64(http.StatusNotFound), records[1]["status"])
+	assert.Equal(t, float64(len(body)), records[1]["bytes"])
+}
+
+// TestWithLogger_NoGlobalLogging tests that a client without a logger writes nothing to the global loggers
+func TestWithLogger_NoGlobalLogging(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(defaultLogger)
+
+	server := setupTLSServer(t, nil)
+	client, err := NewClient(server.URL, WithInsecureSkipVerify())
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Empty(t, output.String())
+}
+
+// TestWithLogger_Nil tests that a nil logger is rejected
+func TestWithLogger_Nil(t *testing.T) {
+	_, err := NewClient("router.lan", WithLogger(nil))
+	assert.EqualError(t, err, "WithLogger: nil logger")
+}
+
+// TestRedactedPayload tests the redaction of the password-like fields of the payloads
+func TestRedactedPayload(t *testing.T) {
+	tests := []struct {
+		payload  string
+		expected string
+	}{
+		{``, ``},
+		{`{"address":"10.0.0.1/24"}`, `{"address":"10.0.0.1/24"}`},
+		{`{"password":"x","Password":"y"}`, `{"password":"REDACTED","Password":"REDACTED"}`},
+		{`{"wpa2-pre-shared-key":"x","private-key":"y","auth-token":"z"}`,
+			`{"wpa2-pre-shared-key":"REDACTED","private-key":"REDACTED","auth-token":"REDACTED"}`},
+		{`[{"name":"a","secret":"x"},{"name":"b","secret":{"nested":"y"}}]`,
+			`[{"name":"a","secret":"REDACTED"},{"name":"b","secret":"REDACTED"}]`},
+		{`{"password":"unterminated`, redacted},
+	}
+
+	for _, test := range tests {
+		value := redactedPayload(test.payload).LogValue().String()
+		if test.expected == "" || test.expected == redacted {
+			assert.Equal(t, test.expected, value)
+			continue
+		}
+		assert.JSONEq(t, test.expected, value)
+	}
+}
+
+// TestRedactedHeader tests the redaction of the sensitive headers
+func TestRedactedHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Basic YWRtaW46cGFzcw==")
+	header.Set("Cookie", "session=1")
+	header.Set("User-Agent", "routeros-client")
+
+	var output bytes.Buffer
+	slog.New(slog.NewTextHandler(&output, nil)).Info("headers", slog.Any("header", redactedHeader(header)))
+
+	assert.Contains(t, output.String(), "header.Authorization=REDACTED")
+	assert.Contains(t, output.String(), "header.Cookie=REDACTED")
+	assert.Contains(t, output.String(), "header.User-Agent=routeros-client")
+	assert.NotContains(t, output.String(), "YWRtaW46cGFzcw==")
+}
@@ -0,0 +1,168 @@
+/*
+Package metrics exports Prometheus metrics of the requests of a RouterOS client.
+A Collector registers the request counts, latency histograms, in-flight gauges and error counts on a Prometheus
+registry, and its Middleware records every request of the clients it is added to.
+example:
+collector, err := metrics.NewCollector(registry)
+routerosv7_restfull_api.NewClient("192.168.88.1", routerosv7_restfull_api.WithMiddleware(collector.Middleware()))
+*/
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
+
+// Labels of the metrics
+const (
+	RouterLabel      = "router"       // RouterLabel is the host and port of the router, e.g. "192.168.88.1:443"
+	VerbLabel        = "verb"         // VerbLabel is the verb of the request, e.g. "print" or "add"
+	StatusClassLabel = "status_class" // StatusClassLabel is the class of the HTTP status, e.g. "2xx", or "error"
+)
+
+// config holds the settings collected from the options
+type config struct {
+	namespace string    // Namespace of the metric names
+	buckets   []float64 // Buckets of the latency histogram in seconds
+}
+
+// Option configures a Collector
+type Option func(*config)
+
+// WithNamespace sets the namespace prefixed to the metric names, "routeros" by default
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace // Set the namespace
+	}
+}
+
+// WithBuckets sets the buckets of the latency histogram in seconds, prometheus.DefBuckets by default
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets // Set the buckets
+	}
+}
+
+// Collector holds the metrics of the requests of RouterOS clients
+type Collector struct {
+	requests *prometheus.CounterVec   // Requests by router, verb and status class
+	duration *prometheus.HistogramVec // Latency of the requests by router, verb and status class
+	inFlight *prometheus.GaugeVec     // Requests in flight by router and verb
+	errors   *prometheus.CounterVec   // Failed requests by router, verb and status class
+}
+
+/*
+NewCollector creates the metrics and registers them on the registerer, returning an error if a metric with the
+same name is already registered, e.g. by another Collector with the same namespace
+*/
+func NewCollector(registerer prometheus.Registerer, opts ...Option) (*Collector, error) {
+
+	// Check if the registerer is nil
+	if registerer == nil {
+		return nil, errors.New("metrics: nil registerer")
+	}
+
+	// Apply the options
+	cfg := config{namespace: "routeros", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	// Create the metrics
+	labels := []string{RouterLabel, VerbLabel, StatusClassLabel}
+	collector := &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "Number of requests sent to RouterOS routers.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests sent to RouterOS routers.",
+			Buckets:   cfg.buckets,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "client",
+			Name:      "requests_in_flight",
+			Help:      "Number of requests waiting for an answer of RouterOS routers.",
+		}, []string{RouterLabel, VerbLabel}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "client",
+			Name:      "request_errors_total",
+			Help:      "Number of requests to RouterOS routers that failed.",
+		}, labels),
+	}
+
+	// Register the metrics
+	for _, metric := range []prometheus.Collector{
+		collector.requests, collector.duration, collector.inFlight, collector.errors,
+	} {
+		if err := registerer.Register(metric); err != nil {
+			return nil, err // Return nil and error
+		}
+	}
+	return collector, nil
+}
+
+// Middleware returns a client middleware recording the metrics of every request
+func (c *Collector) Middleware() routeros.Middleware {
+	return func(next routeros.Doer) routeros.Doer {
+		return routeros.DoerFunc(func(ctx context.Context, request *routeros.Request) (*routeros.Response, error) {
+			router, verb := routerLabel(request.Host), request.Verb()
+
+			// Count the request in flight
+			inFlight := c.inFlight.WithLabelValues(router, verb)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			response, err := next.Do(ctx, request)
+			class := statusClass(response, err)
+
+			// Record the request
+			c.requests.WithLabelValues(router, verb, class).Inc()
+			c.duration.WithLabelValues(router, verb, class).Observe(time.Since(start).Seconds())
+			if err != nil {
+				c.errors.WithLabelValues(router, verb, class).Inc()
+			}
+			return response, err
+		})
+	}
+}
+
+// routerLabel returns the host and port of the router of the endpoint, or the endpoint if it is invalid
+func routerLabel(host string) string {
+	endpoint, err := routeros.ParseEndpoint(host)
+	if err != nil {
+		return host
+	}
+	return endpoint.Address()
+}
+
+// statusClass returns the class of the HTTP status of the response or of the API error, or "error" without status
+func statusClass(response *routeros.Response, err error) string {
+	status := 0
+	var apiErr *routeros.APIError
+	switch {
+	case response != nil:
+		status = response.StatusCode
+	case errors.As(err, &apiErr):
+		status = apiErr.StatusCode
+	}
+
+	// Check if a status was received
+	if status < 100 || status > 599 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
+
+// newMeteredClient creates a client for the server, recording its metrics with the collector
+func newMeteredClient(t *testing.T, collector *Collector, server *httptest.Server) *routeros.Client {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	client, err := routeros.NewClient(strings.TrimPrefix(server.URL, "http://"),
+		routeros.WithMiddleware(collector.Middleware()))
+	require.NoError(t, err)
+	return client
+}
+
+// setupServer creates a server answering the status and body
+func setupServer(t *testing.T, status int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestCollector_Requests tests the counts and latencies of successful and failed requests
+func TestCollector_Requests(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector, err := NewCollector(registry)
+	require.NoError(t, err)
+
+	ok := setupServer(t, http.StatusOK, `[]`)
+	missing := setupServer(t, http.StatusNotFound, `{"error":404}`)
+	router := strings.TrimPrefix(ok.URL, "http://")
+
+	client := newMeteredClient(t, collector, ok)
+	for i := 0; i < 3; i++ {
+		_, err := client.Print(context.Background(), "ip/address")
+		require.NoError(t, err)
+	}
+	_, err = newMeteredClient(t, collector, missing).Remove(context.Background(), "ip/address/*9")
+	require.Error(t, err)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(collector.requests.WithLabelValues(router, "print", "2xx")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(collector.errors.WithLabelValues(router, "print", "2xx")))
+
+	other := strings.TrimPrefix(missing.URL, "http://")
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.requests.WithLabelValues(other, "remove", "4xx")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.errors.WithLabelValues(other, "remove", "4xx")))
+
+	// The latencies are observed with the same labels
+	assert.Equal(t, 2, testutil.CollectAndCount(collector.duration))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.ElementsMatch(t, []string{
+		"routeros_client_requests_total",
+		"routeros_client_request_duration_seconds",
+		"routeros_client_requests_in_flight",
+		"routeros_client_request_errors_total",
+	}, names)
+}
+
+// TestCollector_TransportError tests that a request without response is counted in the "error" class
+func TestCollector_TransportError(t *testing.T) {
+	collector, err := NewCollector(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	server := setupServer(t, http.StatusOK, `[]`)
+	client := newMeteredClient(t, collector, server)
+	server.Close()
+
+	_, err = client.Run(context.Background(), "system/reboot", nil)
+	require.Error(t, err)
+
+	router := strings.TrimPrefix(server.URL, "http://")
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.errors.WithLabelValues(router, "run", "error")))
+}
+
+// TestCollector_InFlight tests the gauge of the requests waiting for an answer
+func TestCollector_InFlight(t *testing.T) {
+	collector, err := NewCollector(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	router := strings.TrimPrefix(server.URL, "http://")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = newMeteredClient(t, collector, server).Print(context.Background(), "ip/address")
+	}()
+
+	gauge := collector.inFlight.WithLabelValues(router, "print")
+	assert.Eventually(t, func() bool { return testutil.ToFloat64(gauge) == 1 }, time.Second, time.Millisecond)
+
+	close(release)
+	<-done
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+}
+
+// TestNewCollector_Options tests the namespace and buckets options
+func TestNewCollector_Options(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector, err := NewCollector(registry, WithNamespace("fleet"), WithBuckets([]float64{0.1, 1}))
+	require.NoError(t, err)
+
+	client := newMeteredClient(t, collector, setupServer(t, http.StatusOK, `[]`))
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		assert.True(t, strings.HasPrefix(family.GetName(), "fleet_client_"), family.GetName())
+		if family.GetName() == "fleet_client_request_duration_seconds" {
+			assert.Len(t, family.GetMetric()[0].GetHistogram().GetBucket(), 2)
+		}
+	}
+}
+
+// TestNewCollector_Errors tests that a nil registerer and a duplicate registration are rejected
+func TestNewCollector_Errors(t *testing.T) {
+	_, err := NewCollector(nil)
+	assert.EqualError(t, err, "metrics: nil registerer")
+
+	registry := prometheus.NewRegistry()
+	_, err = NewCollector(registry)
+	require.NoError(t, err)
+	_, err = NewCollector(registry)
+	assert.Error(t, err)
+}
+
+// TestStatusClass tests the classes of the statuses
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "2xx", statusClass(&routeros.Response{StatusCode: http.StatusNoContent}, nil))
+	assert.Equal(t, "5xx", statusClass(nil, &routeros.APIError{StatusCode: http.StatusServiceUnavailable}))
+	assert.Equal(t, "error", statusClass(nil, context.Canceled))
+	assert.Equal(t, "192.168.88.1:443", routerLabel("https://192.168.88.1"))
+	assert.Equal(t, "not a host", routerLabel("not a host"))
+}
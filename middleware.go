@@ -0,0 +1,164 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Request is a request to the RouterOS REST API as seen by the middlewares
+type Request struct {
+	Method  string      // HTTP method of the request, e.g. MethodGet
+	Host    string      // Endpoint of the router with its scheme, e.g. "https://192.168.88.1"
+	Command string      // Command path of the request, e.g. "ip/address/*1F"
+	Payload []byte      // JSON payload of the request, nil for GET and DELETE
+	Header  http.Header // Extra headers sent with the request, replacing the headers set by the client
+}
+
+// URL returns the URL of the request
+func (r *Request) URL() string {
+	return (&APIRequest{Host: r.Host, Command: r.Command}).URL()
+}
+
+/*
+Verb returns the client verb of the request from its method: "print" for GET, "add" for PUT, "set" for PATCH,
+"remove" for DELETE and "run" for POST
+*/
+func (r *Request) Verb() string {
+	switch r.Method {
+	case MethodGet:
+		return "print"
+	case MethodPut:
+		return "add"
+	case MethodPatch:
+		return "set"
+	case MethodDelete:
+		return "remove"
+	case MethodPost:
+		return "run"
+	}
+	return strings.ToLower(r.Method)
+}
+
+// Response is a successful response of the RouterOS REST API as seen by the middlewares
+type Response struct {
+	StatusCode int    // HTTP status code of the response
+	Body       []byte // Raw JSON body of the response, empty for DELETE
+}
+
+// Doer executes a request, returning the response or an error such as *APIError or *TransportError
+type Doer interface {
+	Do(ctx context.Context, request *Request) (*Response, error)
+}
+
+// DoerFunc is a function implementing Doer
+type DoerFunc func(ctx context.Context, request *Request) (*Response, error)
+
+// Do calls the function
+func (f DoerFunc) Do(ctx context.Context, request *Request) (*Response, error) {
+	return f(ctx, request)
+}
+
+/*
+Middleware wraps the Doer executing the requests of a Client, e.g. to log, measure, modify or fail requests.
+A middleware calls next.Do to execute the request, and may change the request before and the result after.
+*/
+type Middleware func(next Doer) Doer
+
+/*
+WithMiddleware adds middlewares around the execution of every request of the client.
+The first middleware is the outermost one, it sees the request first and the result last.
+*/
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(o *clientOptions) error {
+		// Check if a middleware is nil
+		for _, middleware := range middlewares {
+			if middleware == nil {
+				return errors.New("WithMiddleware: nil middleware")
+			}
+		}
+		o.middlewares = append(o.middlewares, middlewares...) // Add the middlewares
+		return nil
+	}
+}
+
+// chainMiddlewares wraps the doer with the middlewares, the first middleware being the outermost one
+func chainMiddlewares(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
+
+/*
+LoggingMiddleware logs every request with its method, command, status code and duration, at the Info level
+on success and at the Error level on failure. Payloads and credentials are never logged.
+A nil logger logs nothing, the package never logs globally.
+*/
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	log := loggerOrDiscard(logger) // Use the discarding logger if none is provided
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			start := time.Now()
+			response, err := next.Do(ctx, request)
+			attrs := []any{
+				slog.String("method", request.Method),
+				slog.String("command", request.Command),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			// Check if the request failed
+			if err != nil {
+				var apiErr *APIError
+				if errors.As(err, &apiErr) {
+					attrs = append(attrs, slog.Int("status", apiErr.StatusCode))
+				}
+				log.ErrorContext(ctx, "routeros request failed", append(attrs, slog.Any("error", err))...)
+				return response, err
+			}
+
+			log.InfoContext(ctx, "routeros request", append(attrs, slog.Int("status", response.StatusCode))...)
+			return response, nil
+		})
+	}
+}
+
+// RequestTiming is the timing of a request reported by TimingMiddleware
+type RequestTiming struct {
+	Method     string        // HTTP method of the request
+	Command    string        // Command path of the request
+	StatusCode int           // HTTP status code of the response, zero when no response was received
+	Duration   time.Duration // Time taken by the request, including the inner middlewares
+	Err        error         // Error of the request, if any
+}
+
+// TimingMiddleware measures every request and reports its timing to the observe function
+func TimingMiddleware(observe func(ctx context.Context, timing RequestTiming)) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			start := time.Now()
+			response, err := next.Do(ctx, request)
+
+			timing := RequestTiming{
+				Method:   request.Method,
+				Command:  request.Command,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+
+			// Read the status code from the response or from the API error
+			var apiErr *APIError
+			if response != nil {
+				timing.StatusCode = response.StatusCode
+			} else if errors.As(err, &apiErr) {
+				timing.StatusCode = apiErr.StatusCode
+			}
+
+			observe(ctx, timing)
+			return response, err
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package routerosv7_restfull_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMiddleware appends the name of the middleware to the calls before and after the request
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			*calls = append(*calls, name+" before")
+			response, err := next.Do(ctx, request)
+			*calls = append(*calls, name+" after")
+			return response, err
+		})
+	}
+}
+
+// TestWithMiddleware_Order tests that the first middleware is the outermost one
+func TestWithMiddleware_Order(t *testing.T) {
+	server, _ := setupRecordingServer(t, `[]`)
+	var calls []string
+
+	client, err := NewClient(serverHost(server),
+		WithMiddleware(recordingMiddleware("outer", &calls)),
+		WithMiddleware(recordingMiddleware("inner", &calls)),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, calls)
+}
+
+// TestWithMiddleware_SeesRequestAndResult tests that a middleware receives the request and its result
+func TestWithMiddleware_SeesRequestAndResult(t *testing.T) {
+	server, _ := setupRecordingServer(t, `{".id":"*1"}`)
+	var seen []*Request
+	var responses []*Response
+
+	client, err := NewClient(serverHost(server), WithMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			seen = append(seen, request)
+			response, err := next.Do(ctx, request)
+			responses = append(responses, response)
+			return response, err
+		})
+	}))
+	require.NoError(t, err)
+
+	// Both the untyped and the typed verbs go through the middlewares
+	_, err = client.Add(context.Background(), "ip/address", []byte(`{"address":"10.0.0.1/24"}`))
+	require.NoError(t, err)
+	_, err = PrintAs[map[string]string](context.Background(), client, "ip/address/*1")
+	require.NoError(t, err)
+
+	require.Len(t, seen, 2)
+	assert.Equal(t, MethodPut, seen[0].Method)
+	assert.Equal(t, "ip/address", seen[0].Command)
+	assert.Equal(t, `{"address":"10.0.0.1/24"}`, string(seen[0].Payload))
+	assert.Equal(t, "http://"+serverHost(server)+"/rest/ip/address", seen[0].URL())
+	assert.Equal(t, MethodGet, seen[1].Method)
+
+	require.Len(t, responses, 2)
+	assert.Equal(t, http.StatusOK, responses[0].StatusCode)
+	assert.Equal(t, `{".id":"*1"}`, string(responses[0].Body))
+}
+
+// TestWithMiddleware_ModifiesRequest tests that a middleware can change the headers and the command of a request
+func TestWithMiddleware_ModifiesRequest(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(serverHost(server),
+		WithCredentials("admin", "secret"),
+		WithMiddleware(func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+				request.Header.Set("X-Request-Id", "42")
+				request.Header.Set("Authorization", "Bearer token")
+				request.Command = "system/" + request.Command
+				return next.Do(ctx, request)
+			})
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "resource")
+	require.NoError(t, err)
+
+	require.NotNil(t, received)
+	assert.Equal(t, "/rest/system/resource", received.URL.Path)
+	assert.Equal(t, "42", received.Header.Get("X-Request-Id"))
+	assert.Equal(t, "Bearer token", received.Header.Get("Authorization"))
+}
+
+// TestWithMiddleware_FaultInjection tests that a middleware can fail a request without sending it
+func TestWithMiddleware_FaultInjection(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+	injected := errors.New("injected fault")
+
+	client, err := NewClient(serverHost(server), WithMiddleware(func(Doer) Doer {
+		return DoerFunc(func(context.Context, *Request) (*Response, error) {
+			return nil, injected
+		})
+	}))
+	require.NoError(t, err)
+
+	_, err = client.Remove(context.Background(), "ip/address/*1")
+	assert.ErrorIs(t, err, injected)
+	assert.Empty(t, *requests)
+}
+
+// TestWithMiddleware_Nil tests that a nil middleware is rejected
+func TestWithMiddleware_Nil(t *testing.T) {
+	_, err := NewClient("router.lan", WithMiddleware(nil))
+	assert.EqualError(t, err, "WithMiddleware: nil middleware")
+}
+
+// TestLoggingMiddleware tests the records logged for successful and failed requests
+func TestLoggingMiddleware(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, nil))
+
+	client := newMockClient(t, http.StatusNotFound, `{"error":404,"message":"Not Found"}`,
+		WithCredentials("admin", "secret"), WithMiddleware(LoggingMiddleware(logger)))
+
+	_, err := client.Run(context.Background(), "ip/address/print", []byte(`{"password":"hidden"}`))
+	require.Error(t, err)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "routeros request failed", record["msg"])
+	assert.Equal(t, MethodPost, record["method"])
+	assert.Equal(t, "ip/address/print", record["command"])
+	assert.Equal(t, float64(http.StatusNotFound), record["status"])
+	assert.Contains(t, record, "duration")
+	assert.NotContains(t, output.String(), "hidden")
+	assert.NotContains(t, output.String(), "secret")
+}
+
+// TestLoggingMiddleware_Success tests the record logged for a successful request
+func TestLoggingMiddleware_Success(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, nil))
+
+	client := newMockClient(t, http.StatusOK, `[]`, WithMiddleware(LoggingMiddleware(logger)))
+	_, err := client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+}
+
+// TestLoggingMiddleware_NilLogger tests that a nil logger logs nothing, not even to the default logger
+func TestLoggingMiddleware_NilLogger(t *testing.T) {
+	var output bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&output, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	client := newMockClient(t, http.StatusOK, `[]`, WithMiddleware(LoggingMiddleware(nil)))
+	_, err := client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Empty(t, output.String())
+}
+
+// TestTimingMiddleware tests the timings reported for successful and failed requests
+func TestTimingMiddleware(t *testing.T) {
+	var timings []RequestTiming
+	observe := func(_ context.Context, timing RequestTiming) { timings = append(timings, timing) }
+
+	client := newMockClient(t, http.StatusOK, `[]`, WithMiddleware(TimingMiddleware(observe)))
+	_, err := client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	client = newMockClient(t, http.StatusNotFound, `{"error":404}`,
+		WithMiddleware(TimingMiddleware(observe)))
+	_, err = client.Remove(context.Background(), "ip/address/*9")
+	require.Error(t, err)
+
+	require.Len(t, timings, 2)
+	assert.Equal(t, RequestTiming{Method: MethodGet, Command: "ip/address", StatusCode: http.StatusOK,
+		Duration: timings[0].Duration}, timings[0])
+	assert.Positive(t, timings[0].Duration)
+	assert.Equal(t, MethodDelete, timings[1].Method)
+	assert.Equal(t, http.StatusNotFound, timings[1].StatusCode)
+	assert.True(t, IsNotFound(timings[1].Err))
+}
+
+// TestRequest_Verb tests the verb of the requests of every method
+func TestRequest_Verb(t *testing.T) {
+	verbs := map[string]string{
+		MethodGet:    "print",
+		MethodPut:    "add",
+		MethodPatch:  "set",
+		MethodDelete: "remove",
+		MethodPost:   "run",
+		"HEAD":       "head",
+	}
+	for method, verb := range verbs {
+		assert.Equal(t, verb, (&Request{Method: method}).Verb(), method)
+	}
+}
@@ -1,48 +1,198 @@
-// mocks.go
-
+/*
+Package mock provides a testify mock of the routerosv7_restfull_api.API interface, so code depending on the
+interface can be unit tested without a router. The Expect helpers register typed expectations of the requests.
+example:
+api := new(mock.API)
+api.ExpectPrint("ip/address").Return([]interface{}{}, nil)
+result, err := ListAddresses(ctx, api)
+api.AssertExpectations(t)
+*/
 package mock
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
+
 	"github.com/stretchr/testify/mock"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
 )
 
-type RouterOSAPI struct {
+// Anything matches any argument of an expectation
+const Anything = mock.Anything
+
+// API is a mock of the routerosv7_restfull_api.API interface
+type API struct {
 	mock.Mock
 }
 
-func (m *RouterOSAPI) Auth(ctx context.Context, host, username, password string) (interface{}, error) {
-	args := m.Called(ctx, host, username, password)
+// Check if API implements the routerosv7_restfull_api.API interface
+var _ routeros.API = (*API)(nil)
+
+// Call is an expectation of a request registered by an Expect helper
+type Call struct {
+	*mock.Call
+}
+
+// Return sets the result and error returned by the request
+func (c *Call) Return(result interface{}, err error) *Call {
+	c.Call.Return(result, err)
+	return c
+}
+
+// ReturnError sets the error returned by the request, with a nil result
+func (c *Call) ReturnError(err error) *Call {
+	return c.Return(nil, err)
+}
+
+// Once expects the request only once
+func (c *Call) Once() *Call {
+	c.Call.Once()
+	return c
+}
+
+// Times expects the request n times
+func (c *Call) Times(n int) *Call {
+	c.Call.Times(n)
+	return c
+}
+
+// expect registers an expectation of the method with any context and the arguments
+func (m *API) expect(method string, arguments ...interface{}) *Call {
+	return &Call{Call: m.On(method, append([]interface{}{Anything}, arguments...)...)}
+}
+
+// ExpectAuth expects a call of Auth
+func (m *API) ExpectAuth() *Call {
+	return m.expect("Auth")
+}
+
+// ExpectPrint expects a call of Print with the command, or any argument matcher such as Anything
+func (m *API) ExpectPrint(command interface{}) *Call {
+	return m.expect("Print", command)
+}
+
+// ExpectAdd expects a call of Add with the command and payload, e.g. JSONPayload(`{"address":"10.0.0.1/24"}`)
+func (m *API) ExpectAdd(command, payload interface{}) *Call {
+	return m.expect("Add", command, payload)
+}
+
+// ExpectSet expects a call of Set with the command and payload
+func (m *API) ExpectSet(command, payload interface{}) *Call {
+	return m.expect("Set", command, payload)
+}
+
+// ExpectRemove expects a call of Remove with the command
+func (m *API) ExpectRemove(command interface{}) *Call {
+	return m.expect("Remove", command)
+}
+
+// ExpectRun expects a call of Run with the command and payload
+func (m *API) ExpectRun(command, payload interface{}) *Call {
+	return m.expect("Run", command, payload)
+}
+
+// ExpectAddPayload expects a call of AddPayload with the command and payload value
+func (m *API) ExpectAddPayload(command, payload interface{}) *Call {
+	return m.expect("AddPayload", command, payload)
+}
+
+// ExpectSetPayload expects a call of SetPayload with the command and payload value
+func (m *API) ExpectSetPayload(command, payload interface{}) *Call {
+	return m.expect("SetPayload", command, payload)
+}
+
+// ExpectRunPayload expects a call of RunPayload with the command and payload value
+func (m *API) ExpectRunPayload(command, payload interface{}) *Call {
+	return m.expect("RunPayload", command, payload)
+}
+
+// ExpectRunQuery expects a call of RunQuery with the command and query
+func (m *API) ExpectRunQuery(command, query interface{}) *Call {
+	return m.expect("RunQuery", command, query)
+}
+
+// ExpectRunFilter expects a call of RunFilter with the command and filter expression
+func (m *API) ExpectRunFilter(command, filter interface{}) *Call {
+	return m.expect("RunFilter", command, filter)
+}
+
+/*
+JSONPayload matches a JSON payload equal to the expected JSON regardless of the order of the fields and the
+whitespace, e.g. JSONPayload(`{"address":"10.0.0.1/24","interface":"ether1"}`)
+*/
+func JSONPayload(expected string) interface{} {
+	return mock.MatchedBy(func(payload []byte) bool {
+		var want, got interface{}
+		if json.Unmarshal([]byte(expected), &want) != nil || json.Unmarshal(payload, &got) != nil {
+			return false
+		}
+		return reflect.DeepEqual(want, got)
+	})
+}
+
+// Auth records a call of Auth and returns the expected result and error
+func (m *API) Auth(ctx context.Context) (interface{}, error) {
+	args := m.Called(ctx)
+	return args.Get(0), args.Error(1)
+}
+
+// Print records a call of Print and returns the expected result and error
+func (m *API) Print(ctx context.Context, command string) (interface{}, error) {
+	args := m.Called(ctx, command)
+	return args.Get(0), args.Error(1)
+}
+
+// Add records a call of Add and returns the expected result and error
+func (m *API) Add(ctx context.Context, command string, payload []byte) (interface{}, error) {
+	args := m.Called(ctx, command, payload)
+	return args.Get(0), args.Error(1)
+}
+
+// Set records a call of Set and returns the expected result and error
+func (m *API) Set(ctx context.Context, command string, payload []byte) (interface{}, error) {
+	args := m.Called(ctx, command, payload)
+	return args.Get(0), args.Error(1)
+}
+
+// Remove records a call of Remove and returns the expected result and error
+func (m *API) Remove(ctx context.Context, command string) (interface{}, error) {
+	args := m.Called(ctx, command)
+	return args.Get(0), args.Error(1)
+}
+
+// Run records a call of Run and returns the expected result and error
+func (m *API) Run(ctx context.Context, command string, payload []byte) (interface{}, error) {
+	args := m.Called(ctx, command, payload)
 	return args.Get(0), args.Error(1)
 }
 
-func (m *RouterOSAPI) Print(ctx context.Context, host, username, password, command string) (interface{}, error) {
-	args := m.Called(ctx, host, username, password, command)
+// AddPayload records a call of AddPayload and returns the expected result and error
+func (m *API) AddPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) {
+	args := m.Called(ctx, command, payload)
 	return args.Get(0), args.Error(1)
 }
 
-func (m *RouterOSAPI) Add(
-	ctx context.Context, host, username, password, command string, payload []byte,
-) (interface{}, error) {
-	args := m.Called(ctx, host, username, password, command, payload)
+// SetPayload records a call of SetPayload and returns the expected result and error
+func (m *API) SetPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) {
+	args := m.Called(ctx, command, payload)
 	return args.Get(0), args.Error(1)
 }
 
-func (m *RouterOSAPI) Set(
-	ctx context.Context, host, username, password, command string, payload []byte,
-) (interface{}, error) {
-	args := m.Called(ctx, host, username, password, command, payload)
+// RunPayload records a call of RunPayload and returns the expected result and error
+func (m *API) RunPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) {
+	args := m.Called(ctx, command, payload)
 	return args.Get(0), args.Error(1)
 }
 
-func (m *RouterOSAPI) Remove(ctx context.Context, host, username, password, command string) (interface{}, error) {
-	args := m.Called(ctx, host, username, password, command)
+// RunQuery records a call of RunQuery and returns the expected result and error
+func (m *API) RunQuery(ctx context.Context, command string, query *routeros.Query) (interface{}, error) {
+	args := m.Called(ctx, command, query)
 	return args.Get(0), args.Error(1)
 }
 
-func (m *RouterOSAPI) Run(
-	ctx context.Context, host, username, password, command string, payload []byte,
-) (interface{}, error) {
-	args := m.Called(ctx, host, username, password, command, payload)
+// RunFilter records a call of RunFilter and returns the expected result and error
+func (m *API) RunFilter(ctx context.Context, command, filter string) (interface{}, error) {
+	args := m.Called(ctx, command, filter)
 	return args.Get(0), args.Error(1)
 }
@@ -2,158 +2,121 @@ package mock
 
 import (
 	"context"
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"errors"
+	"fmt"
 	"testing"
-)
-
-func TestAuth(t *testing.T) {
-	// Create a mock API
-	mockAPI := new(RouterOSAPI)
-
-	// Set up the expected result and error
-	expectedResult := map[string]interface{}{"result": "success"}
-	var expectedError error // Change this to the expected error if any
-
-	// Set up the expectations for the Auth method
-	mockAPI.On("Auth", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(expectedResult, expectedError)
 
-	// Execute the Auth function using the mock API
-	result, err := mockAPI.Auth(context.Background(), "host", "user", "password")
-
-	// Assert that the expectations were met
-	mockAPI.AssertExpectations(t)
-
-	// Check for errors
-	assert.NoError(t, err, "Auth function returned an error")
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
 
-	// Verify the result
-	assert.Equal(t, expectedResult, result, "Auth function result does not match expected result")
+// addressCount is code under test depending on the API interface
+func addressCount(ctx context.Context, api routeros.API) (int, error) {
+	result, err := api.Print(ctx, "ip/address")
+	if err != nil {
+		return 0, err
+	}
+	addresses, ok := result.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("unexpected result %T", result)
+	}
+	return len(addresses), nil
 }
 
-func TestPrint(t *testing.T) {
-	// Create a mock API
-	mockAPI := new(RouterOSAPI)
-
-	// Set up the expected result and error
-	expectedResult := map[string]interface{}{"result": "success"}
-	var expectedError error // Change this to the expected error if any
-
-	// Set up the expectations for the Print method
-	mockAPI.On("Print", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(expectedResult, expectedError)
-
-	// Execute the Print function using the mock API
-	result, err := mockAPI.Print(context.Background(), "host", "user", "password", "command")
-
-	// Assert that the expectations were met
-	mockAPI.AssertExpectations(t)
-
-	// Check for errors
-	assert.NoError(t, err, "Print function returned an error")
-
-	// Verify the result
-	assert.Equal(t, expectedResult, result, "Print function result does not match expected result")
+// recordingT records the failures of the expectations instead of failing the test
+type recordingT struct {
+	failed bool
 }
 
-func TestAdd(t *testing.T) {
-	// Create a mock API
-	mockAPI := new(RouterOSAPI)
-
-	// Set up the expected result and error
-	expectedResult := map[string]interface{}{"result": "success"}
-	var expectedError error // Change this to the expected error if any
-
-	// Set up the expectations for the Add method
-	mockAPI.On("Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(expectedResult, expectedError)
-
-	// Execute the Add function using the mock API
-	result, err := mockAPI.Add(context.Background(), "host", "user", "password", "command",
-		[]byte("payload"))
+func (t *recordingT) Logf(string, ...interface{})   {}
+func (t *recordingT) Errorf(string, ...interface{}) { t.failed = true }
+func (t *recordingT) FailNow()                      { t.failed = true }
 
-	// Assert that the expectations were met
-	mockAPI.AssertExpectations(t)
+// TestAPI_SwapsClient tests that the mock replaces the client in code depending on the API interface
+func TestAPI_SwapsClient(t *testing.T) {
+	api := new(API)
+	api.ExpectPrint("ip/address").Return([]interface{}{map[string]interface{}{".id": "*1"}}, nil).Once()
 
-	// Check for errors
-	assert.NoError(t, err, "Add function returned an error")
-
-	// Verify the result
-	assert.Equal(t, expectedResult, result, "Add function result does not match expected result")
+	count, err := addressCount(context.Background(), api)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	api.AssertExpectations(t)
 }
 
-func TestSet(t *testing.T) {
-	// Create a mock API
-	mockAPI := new(RouterOSAPI)
-
-	// Set up the expected result and error
-	expectedResult := map[string]interface{}{"result": "success"}
-	var expectedError error // Change this to the expected error if any
-
-	// Set up the expectations for the Set method
-	mockAPI.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(expectedResult, expectedError)
-
-	// Execute the Set function using the mock API
-	result, err := mockAPI.Set(context.Background(), "host", "user", "password", "command", []byte("payload"))
-
-	// Assert that the expectations were met
-	mockAPI.AssertExpectations(t)
+// TestAPI_ReturnError tests an expectation returning an error
+func TestAPI_ReturnError(t *testing.T) {
+	api := new(API)
+	notFound := &routeros.APIError{StatusCode: 404, Message: "Not Found"}
+	api.ExpectRemove("ip/address/*9").ReturnError(notFound)
 
-	// Check for errors
-	assert.NoError(t, err, "Set function returned an error")
-
-	// Verify the result
-	assert.Equal(t, expectedResult, result, "Set function result does not match expected result")
+	result, err := api.Remove(context.Background(), "ip/address/*9")
+	assert.Nil(t, result)
+	assert.True(t, routeros.IsNotFound(err))
+	api.AssertExpectations(t)
 }
 
-func TestRemove(t *testing.T) {
-	// Create a mock API
-	mockAPI := new(RouterOSAPI)
-
-	// Set up the expected result and error
-	expectedResult := map[string]interface{}{"result": "success"}
-	var expectedError error // Change this to the expected error if any
-
-	// Set up the expectations for the Remove method
-	mockAPI.On("Remove", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(expectedResult, expectedError)
-
-	// Execute the Remove function using the mock API
-	result, err := mockAPI.Remove(context.Background(), "host", "user", "password", "command")
-
-	// Assert that the expectations were met
-	mockAPI.AssertExpectations(t)
-
-	// Check for errors
-	assert.NoError(t, err, "Remove function returned an error")
-
-	// Verify the result
-	assert.Equal(t, expectedResult, result, "Remove function result does not match expected result")
+// TestAPI_Expectations tests the expectations of every method
+func TestAPI_Expectations(t *testing.T) {
+	api := new(API)
+	ctx := context.Background()
+	query := routeros.NewQuery().Where("disabled", "false")
+	failure := errors.New("failure")
+
+	api.ExpectAuth().Return(map[string]interface{}{"version": "7.14"}, nil)
+	api.ExpectAdd("ip/address", JSONPayload(`{"interface":"ether1","address":"10.0.0.1/24"}`)).Return("*1", nil)
+	api.ExpectSet("ip/address/*1", []byte(`{"disabled":"true"}`)).Return(nil, nil)
+	api.ExpectRun("system/reboot", Anything).ReturnError(failure)
+	api.ExpectAddPayload("ip/pool", Anything).Return("*2", nil)
+	api.ExpectSetPayload("ip/pool/*2", Anything).Return(nil, nil)
+	api.ExpectRunPayload("ping", Anything).Return([]interface{}{}, nil)
+	api.ExpectRunQuery("ip/address/print", query).Return([]interface{}{}, nil)
+	api.ExpectRunFilter("ip/address/print", `disabled="false"`).Return([]interface{}{}, nil).Times(2)
+
+	_, err := api.Auth(ctx)
+	assert.NoError(t, err)
+	result, err := api.Add(ctx, "ip/address", []byte(`{"address": "10.0.0.1/24", "interface": "ether1"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "*1", result)
+	_, err = api.Set(ctx, "ip/address/*1", []byte(`{"disabled":"true"}`))
+	assert.NoError(t, err)
+	_, err = api.Run(ctx, "system/reboot", nil)
+	assert.Equal(t, failure, err)
+	_, err = api.AddPayload(ctx, "ip/pool", map[string]string{"name": "dhcp"})
+	assert.NoError(t, err)
+	_, err = api.SetPayload(ctx, "ip/pool/*2", map[string]string{"name": "lan"})
+	assert.NoError(t, err)
+	_, err = api.RunPayload(ctx, "ping", map[string]string{"address": "10.0.0.1"})
+	assert.NoError(t, err)
+	_, err = api.RunQuery(ctx, "ip/address/print", query)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = api.RunFilter(ctx, "ip/address/print", `disabled="false"`)
+		assert.NoError(t, err)
+	}
+	api.AssertExpectations(t)
 }
 
-func TestRun(t *testing.T) {
-	// Create a mock API
-	mockAPI := new(RouterOSAPI)
-
-	// Set up the expected result and error
-	expectedResult := map[string]interface{}{"result": "success"}
-	var expectedError error // Change this to the expected error if any
+// TestAPI_UnmetExpectation tests that an expected request which is not made fails the assertions
+func TestAPI_UnmetExpectation(t *testing.T) {
+	api := new(API)
+	api.ExpectPrint(Anything).Return([]interface{}{}, nil)
 
-	// Set up the expectations for the Run method
-	mockAPI.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(expectedResult, expectedError)
-
-	// Execute the Run function using the mock API
-	result, err := mockAPI.Run(context.Background(), "host", "user", "password", "command", []byte("payload"))
-
-	// Assert that the expectations were met
-	mockAPI.AssertExpectations(t)
-
-	// Check for errors
-	assert.NoError(t, err, "Run function returned an error")
+	recorder := &recordingT{}
+	assert.False(t, api.AssertExpectations(recorder))
+	assert.True(t, recorder.failed)
+}
 
-	// Verify the result
-	assert.Equal(t, expectedResult, result, "Run function result does not match expected result")
+// TestJSONPayload tests the matching of the JSON payloads
+func TestJSONPayload(t *testing.T) {
+	api := new(API)
+	api.ExpectAdd("ip/address", JSONPayload(`{"address":"10.0.0.1/24"}`)).Return("*1", nil)
+
+	// A different payload does not match the expectation
+	assert.Panics(t, func() {
+		_, _ = api.Add(context.Background(), "ip/address", []byte(`{"address":"10.0.0.2/24"}`))
+	})
+	assert.Panics(t, func() {
+		_, _ = api.Add(context.Background(), "ip/address", []byte(`not json`))
+	})
 }
@@ -0,0 +1,114 @@
+package routerosv7_restfull_api
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// clientOptions holds the configuration collected from the functional options passed to NewClient
+type clientOptions struct {
+	username  string            // Username for the request to Mikrotik Router
+	password  string            // Password for the request to Mikrotik Router
+	timeout   time.Duration     // Timeout for every request made by the client
+	tlsConfig *tls.Config       // TLS configuration used by the HTTP transport
+	transport http.RoundTripper // Transport used instead of the one built by the client
+	userAgent string            // User-Agent header sent with every request
+	scheme    string            // Scheme overriding the scheme of the endpoint
+	tls       tlsOptions        // TLS settings applied on top of the TLS configuration
+	pool      transportOptions  // Connection pooling settings of the transport
+
+	protocolPolicy ProtocolPolicy       // Protocol policy of the client
+	downgradeHook  func(DowngradeEvent) // Function called before a request is downgraded to HTTP
+	protocolCache  *ProtocolCache       // Cache of the protocol detected by ProtocolAutoDetect
+	httpsPort      string               // HTTPS port probed by ProtocolAutoDetect
+	httpPort       string               // HTTP port probed by ProtocolAutoDetect
+	probeTimeout   time.Duration        // Deadline for probing the ports of the router
+	middlewares    []Middleware         // Middlewares wrapping the execution of every request
+	retryPolicy    *RetryPolicy         // Retry policy of the idempotent requests, nil to disable the retries
+	rate           float64              // Requests per second allowed by the rate limit, zero for no limit
+	burst          int                  // Burst of requests allowed by the rate limit
+	maxInFlight    int                  // Maximum number of requests in flight, zero for no limit
+	limiter        *Limiter             // Limiter shared with other clients of the same router
+	circuitBreaker *CircuitBreaker      // Circuit breaker failing the requests to an unreachable router
+	logger         *slog.Logger         // Logger of the client, nil to discard the logs
+}
+
+// Option configures a Client created by NewClient
+type Option func(*clientOptions) error
+
+// WithCredentials sets the username and password used for BasicAuth on every request
+func WithCredentials(username, password string) Option {
+	return func(o *clientOptions) error {
+		o.username = username // Set the username
+		o.password = password // Set the password
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout for every request made by the client, zero means no timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) error {
+		// Check if the timeout is negative
+		if timeout < 0 {
+			return errors.New("WithTimeout: timeout must not be negative")
+		}
+		o.timeout = timeout // Set the timeout
+		return nil
+	}
+}
+
+/*
+WithTLSConfig sets the TLS configuration used by the transport built by the client.
+The other TLS options, such as WithRootCAsPEM, are applied on top of it whatever their order.
+*/
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *clientOptions) error {
+		// Check if the TLS configuration is nil
+		if config == nil {
+			return errors.New("WithTLSConfig: nil TLS config")
+		}
+		o.tlsConfig = config.Clone() // Clone the TLS configuration so the caller can keep modifying its copy
+		return nil
+	}
+}
+
+/*
+WithTransport sets the http.RoundTripper used by the client.
+When set, the TLS configuration and the TLS options are ignored because the transport is fully owned by the caller.
+*/
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) error {
+		// Check if the transport is nil
+		if transport == nil {
+			return errors.New("WithTransport: nil transport")
+		}
+		o.transport = transport // Set the transport
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) error {
+		o.userAgent = userAgent // Set the User-Agent
+		return nil
+	}
+}
+
+// WithScheme sets the scheme used to talk to the router, "http" or "https", overriding the scheme of the host
+func WithScheme(scheme string) Option {
+	return func(o *clientOptions) error {
+		// Check if the scheme is supported
+		scheme = strings.ToLower(scheme)
+		if scheme != httpProtocol && scheme != httpsProtocol {
+			return fmt.Errorf("WithScheme: unsupported scheme %q", scheme)
+		}
+		o.scheme = scheme // Set the scheme
+		return nil
+	}
+}
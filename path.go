@@ -0,0 +1,153 @@
+package routerosv7_restfull_api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+Path builds the command path of a request, percent-encoding every segment and GET filter parameter so that
+values containing spaces, "&", "#" or "/" cannot corrupt the request. The "*" of RouterOS item ids such as
+"*1F" is kept as is.
+example:
+
+	NewPath("ip/address").ID("*1F").String()                         // ip/address/*1F
+	NewPath("ip/address").Filter("comment", "office & lab").String() // ip/address?comment=office+%26+lab
+	NewPath("interface/wireguard/peers").Segment("wg/0").String()    // interface/wireguard/peers/wg%2F0
+*/
+type Path struct {
+	segments []string   // Unescaped segments of the path
+	query    url.Values // GET filter parameters
+}
+
+/*
+NewPath creates a Path from a command such as "ip/address". The command is split on "/" and its segments
+are escaped, so it must not contain item ids or other values, which are added with Segment or ID.
+*/
+func NewPath(command string) *Path {
+	path := &Path{query: url.Values{}}
+	for _, segment := range strings.Split(command, "/") {
+		if segment != "" {
+			path.segments = append(path.segments, segment)
+		}
+	}
+	return path
+}
+
+// Segment appends a single segment to the path, escaping any "/" it contains
+func (p *Path) Segment(segment string) *Path {
+	p.segments = append(p.segments, segment)
+	return p
+}
+
+// ID appends the internal id of an item, such as "*1F", to the path
+func (p *Path) ID(id string) *Path {
+	return p.Segment(id)
+}
+
+// Filter adds a GET filter parameter matching items whose property equals the value
+func (p *Path) Filter(name, value string) *Path {
+	p.query.Add(name, value)
+	return p
+}
+
+// Proplist sets the properties returned by a GET request
+func (p *Path) Proplist(fields ...string) *Path {
+	p.query.Set(".proplist", strings.Join(fields, ","))
+	return p
+}
+
+// String returns the escaped command path, followed by the filter parameters if any
+func (p *Path) String() string {
+	escaped := make([]string, len(p.segments))
+	for i, segment := range p.segments {
+		escaped[i] = escapePathSegment(segment)
+	}
+
+	command := strings.Join(escaped, "/")
+	if len(p.query) > 0 {
+		command += "?" + p.query.Encode()
+	}
+	return command
+}
+
+// escapePathSegment percent-encodes a path segment, keeping the "*" of item ids
+func escapePathSegment(segment string) string {
+	return strings.ReplaceAll(url.PathEscape(segment), "%2A", "*")
+}
+
+/*
+escapeCommand escapes a command given as a string, e.g. built with fmt.Sprintf. Such a command is taken as already
+encoded like the output of Path.String: its percent-escapes are sent as is and a "+" in a filter parameter is a
+space, so a literal "+" or "%" must be written "%2B" or "%25". Only the characters that cannot appear in a URL,
+such as spaces, "#" or a "%" that does not start an escape, are escaped. Raw values should be added with Path.
+*/
+func escapeCommand(command string) string {
+	path, query, hasQuery := strings.Cut(command, "?")
+
+	// Escape every segment of the path
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escapeInvalid(segment, isPathSegmentByte, "%20")
+	}
+	escaped := strings.Join(segments, "/")
+
+	// Check if there are no filter parameters
+	if !hasQuery {
+		return escaped
+	}
+
+	// Escape every name and value of the filter parameters, keeping their order
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, value, hasValue := strings.Cut(param, "=")
+		params[i] = escapeInvalid(name, isQueryComponentByte, "+")
+		if hasValue {
+			params[i] += "=" + escapeInvalid(value, isQueryComponentByte, "+")
+		}
+	}
+	return escaped + "?" + strings.Join(params, "&")
+}
+
+/*
+escapeInvalid percent-encodes the bytes of an encoded component that are not allowed by the function, keeping the
+valid percent-escapes and writing the spaces as given
+*/
+func escapeInvalid(component string, allowed func(byte) bool, space string) string {
+	var builder strings.Builder
+	for i := 0; i < len(component); i++ {
+		c := component[i]
+		switch {
+		case c == '%' && i+2 < len(component) && isHex(component[i+1]) && isHex(component[i+2]):
+			builder.WriteByte(c) // Keep the valid percent-escape
+		case c == ' ':
+			builder.WriteString(space)
+		case allowed(c):
+			builder.WriteByte(c)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", c)
+		}
+	}
+	return builder.String()
+}
+
+// isUnreservedByte checks if the byte is an unreserved URL character
+func isUnreservedByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0
+}
+
+// isPathSegmentByte checks if the byte may appear unescaped in a path segment
+func isPathSegmentByte(c byte) bool {
+	return isUnreservedByte(c) || strings.IndexByte("!$&'()*+,;=:@", c) >= 0
+}
+
+// isQueryComponentByte checks if the byte may appear unescaped in a name or value of a filter parameter
+func isQueryComponentByte(c byte) bool {
+	return isUnreservedByte(c) || strings.IndexByte("!$'()*+,;:@/?", c) >= 0
+}
+
+// isHex checks if the byte is a hexadecimal digit
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,113 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPath_String tests the escaped command paths built by Path
+func TestPath_String(t *testing.T) {
+	tests := []struct {
+		name     string // Test case name
+		path     *Path  // Path under test
+		expected string // Expected command
+	}{
+		{"Command", NewPath("ip/address"), "ip/address"},
+		{"Leading and trailing slashes", NewPath("/ip/address/"), "ip/address"},
+		{"Item id", NewPath("ip/address").ID("*1F"), "ip/address/*1F"},
+		{"Segment with slash", NewPath("interface/wireguard/peers").Segment("wg/0"), "interface/wireguard/peers/wg%2F0"},
+		{"Segment with space and hash", NewPath("file").Segment("my backup#1"), "file/my%20backup%231"},
+		{"Segment with question mark", NewPath("file").Segment("a?b"), "file/a%3Fb"},
+		{"Filter", NewPath("ip/address").Filter("address", "192.168.88.1/24"),
+			"ip/address?address=192.168.88.1%2F24"},
+		{"Filter with ampersand and hash", NewPath("ip/address").Filter("comment", "office & lab #2"),
+			"ip/address?comment=office+%26+lab+%232"},
+		{"Filters are sorted", NewPath("ip/address").Filter("interface", "ether1").Filter("disabled", "false"),
+			"ip/address?disabled=false&interface=ether1"},
+		{"Proplist", NewPath("ip/address").Proplist("address", "interface"),
+			"ip/address?.proplist=address%2Cinterface"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.path.String())
+
+			// Escaping an escaped command leaves it unchanged
+			assert.Equal(t, tt.expected, escapeCommand(tt.expected))
+		})
+	}
+}
+
+// TestEscapeCommand tests the escaping of commands built by hand
+func TestEscapeCommand(t *testing.T) {
+	tests := []struct {
+		name     string // Test case name
+		command  string // Command under test
+		expected string // Expected escaped command
+	}{
+		{"Plain command", "ip/address/print", "ip/address/print"},
+		{"Item id", "ip/address/*1F", "ip/address/*1F"},
+		{"Space", "file/my backup", "file/my%20backup"},
+		{"Hash in path", "file/backup#1", "file/backup%231"},
+		{"Filter parameters keep their order", "ip/address?interface=ether1&disabled=false",
+			"ip/address?interface=ether1&disabled=false"},
+		{"Hash in filter", "ip/address?comment=a#b", "ip/address?comment=a%23b"},
+		{"Space in filter", "ip/address?comment=a b", "ip/address?comment=a+b"},
+		{"Parameter without value", "ip/address?comment", "ip/address?comment"},
+		{"Invalid escape is kept literally", "file/100%", "file/100%25"},
+		{"Plus in filter is sent as is", "ip/address?comment=a+b", "ip/address?comment=a+b"},
+		{"Escaped plus in filter", "ip/address?comment=a%2Bb", "ip/address?comment=a%2Bb"},
+		{"Escape in filter is not decoded", "ip/address?comment=%41", "ip/address?comment=%41"},
+		{"Lone percent in filter", "ip/address?comment=50%", "ip/address?comment=50%25"},
+		{"Escape in path is not decoded", "file/%41", "file/%41"},
+		{"Non-ASCII", "ip/address?comment=café", "ip/address?comment=caf%C3%A9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, escapeCommand(tt.command))
+		})
+	}
+}
+
+// TestClient_PathRequest tests that a command built with Path reaches the server with the original values
+func TestClient_PathRequest(t *testing.T) {
+	var received *http.Request
+
+	// Create a server recording the request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(),
+		NewPath("ip/address").Filter("comment", "office & lab #2").Filter("disabled", "false").String())
+	require.NoError(t, err)
+
+	require.NotNil(t, received)
+	assert.Equal(t, "/rest/ip/address", received.URL.Path)
+	assert.Equal(t, "office & lab #2", received.URL.Query().Get("comment"))
+	assert.Equal(t, "false", received.URL.Query().Get("disabled"))
+
+	_, err = client.Remove(context.Background(), NewPath("file").Segment("a/b c").String())
+	require.NoError(t, err)
+	assert.Equal(t, "/rest/file/a%2Fb%20c", received.URL.EscapedPath())
+
+	// A plus reaches the router as a plus, whether added with Path or escaped by hand
+	_, err = client.Print(context.Background(), NewPath("ip/address").Filter("comment", "a+b %41").String())
+	require.NoError(t, err)
+	assert.Equal(t, "a+b %41", received.URL.Query().Get("comment"))
+	_, err = client.Print(context.Background(), "ip/address?comment=a%2Bb%2541")
+	require.NoError(t, err)
+	assert.Equal(t, "a+b%41", received.URL.Query().Get("comment"))
+}
@@ -0,0 +1,320 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sumitroajiprabowo/routerosv7-restfull-api/ros"
+)
+
+// payloadTag is the struct tag holding the RouterOS field name of a struct field
+const payloadTag = "ros"
+
+// fieldNamePattern matches RouterOS property names such as "address" or "dst-address"
+var fieldNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// specialFields are the RouterOS field names starting with a dot accepted in a payload
+var specialFields = map[string]bool{
+	".id":       true, // .id is the internal id of an item
+	".proplist": true, // .proplist is the list of properties returned by print
+	".query":    true, // .query is the query stack of print
+}
+
+// PayloadError is returned when a payload cannot be encoded, before the request is sent
+type PayloadError struct {
+	Field  string // RouterOS field name, or Go field name when the RouterOS name is missing
+	Reason string // Reason why the field was rejected
+}
+
+// Error returns the field and the reason why it was rejected
+func (e *PayloadError) Error() string {
+	return fmt.Sprintf("payload: field %q: %s", e.Field, e.Reason)
+}
+
+/*
+EncodePayload encodes a map with string keys, or a struct whose fields carry a `ros:"field"` tag,
+into a RouterOS JSON payload for PUT (add) and POST (command) requests.
+Booleans are encoded as "yes" or "no", numbers and durations as RouterOS strings, slices as comma separated
+lists, and nil values are omitted. Struct fields tagged `ros:"field,omitempty"` are omitted when zero,
+and fields tagged `ros:"-"` are ignored. Empty, malformed or duplicate field names are rejected.
+example:
+
+	type Address struct {
+		Address   string `ros:"address"`
+		Interface string `ros:"interface"`
+		Disabled  bool   `ros:"disabled"`
+		Comment   string `ros:"comment,omitempty"`
+	}
+*/
+func EncodePayload(v interface{}) ([]byte, error) {
+	return encodePayload(v, false)
+}
+
+/*
+EncodePatchPayload encodes a payload like EncodePayload for PATCH (set) requests, omitting every zero value
+so that only the fields set by the caller are changed. Use a pointer field to set a zero value explicitly.
+*/
+func EncodePatchPayload(v interface{}) ([]byte, error) {
+	return encodePayload(v, true)
+}
+
+// encodePayload encodes the payload, omitting every zero value if omitZero is true
+func encodePayload(v interface{}, omitZero bool) ([]byte, error) {
+
+	// Collect the RouterOS fields of the payload
+	fields, err := payloadFields(reflect.ValueOf(v), omitZero)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode the fields as a JSON object
+	return json.Marshal(fields)
+}
+
+// payloadFields collects the RouterOS fields of a map or a struct
+func payloadFields(value reflect.Value, omitZero bool) (map[string]interface{}, error) {
+
+	// Dereference pointers and interfaces
+	value = indirect(value)
+	if !value.IsValid() {
+		return nil, errors.New("payload: nil payload")
+	}
+
+	fields := make(map[string]interface{})
+
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("payload: unsupported map key type %s", value.Type().Key())
+		}
+		for iter := value.MapRange(); iter.Next(); {
+			if err := addPayloadField(fields, iter.Key().String(), iter.Value(), omitZero); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Struct:
+		if err := addStructFields(fields, value, omitZero); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("payload: unsupported payload type %s", value.Type())
+	}
+
+	return fields, nil
+}
+
+// addStructFields adds the tagged fields of a struct, flattening embedded structs
+func addStructFields(fields map[string]interface{}, value reflect.Value, omitZero bool) error {
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, tagged := field.Tag.Lookup(payloadTag)
+
+		// Flatten untagged embedded structs
+		if field.Anonymous && !tagged && indirectType(field.Type).Kind() == reflect.Struct {
+			embedded := indirect(value.Field(i))
+			if embedded.IsValid() {
+				if err := addStructFields(fields, embedded, omitZero); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// Ignore unexported and explicitly ignored fields
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		// Check if the field has a RouterOS name
+		if !tagged {
+			return &PayloadError{Field: field.Name, Reason: "missing ros tag"}
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return &PayloadError{Field: field.Name, Reason: "empty field name"}
+		}
+
+		// Omit zero values when requested
+		fieldValue := value.Field(i)
+		if options == "omitempty" && fieldValue.IsZero() {
+			continue
+		}
+
+		if err := addPayloadField(fields, name, fieldValue, omitZero); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addPayloadField validates the field name, encodes the value and adds it to the fields
+func addPayloadField(fields map[string]interface{}, name string, value reflect.Value, omitZero bool) error {
+
+	// Validate the field name
+	if err := validateFieldName(name); err != nil {
+		return err
+	}
+
+	// Check if the field is already set, e.g. by an embedded struct
+	if _, exists := fields[name]; exists {
+		return &PayloadError{Field: name, Reason: "duplicate field"}
+	}
+
+	// Omit nil values and, when requested, zero values. Values of a map are never zero because they are
+	// held in an interface, so an explicit "" in a map is still sent
+	if isNilValue(value) || value.Kind() == reflect.Interface && isNilValue(value.Elem()) ||
+		omitZero && value.Kind() != reflect.Pointer && value.IsZero() {
+		return nil
+	}
+
+	// Encode the value
+	encoded, err := encodePayloadValue(indirect(value), strings.HasPrefix(name, "."))
+	if err != nil {
+		return &PayloadError{Field: name, Reason: err.Error()}
+	}
+
+	fields[name] = encoded
+	return nil
+}
+
+// validateFieldName checks that the name is a RouterOS property name or one of the special dot fields
+func validateFieldName(name string) error {
+	switch {
+	case name == "":
+		return &PayloadError{Field: name, Reason: "empty field name"}
+	case strings.HasPrefix(name, "."):
+		if !specialFields[name] {
+			return &PayloadError{Field: name, Reason: "unknown special field"}
+		}
+	case !fieldNamePattern.MatchString(name):
+		return &PayloadError{Field: name, Reason: "invalid field name"}
+	}
+	return nil
+}
+
+// Types with a dedicated encoding
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+/*
+encodePayloadValue encodes a value as a RouterOS string. Slices are encoded as comma separated lists,
+except for the special dot fields which RouterOS expects as JSON arrays.
+*/
+func encodePayloadValue(value reflect.Value, keepArray bool) (interface{}, error) {
+
+	// Use the dedicated encoding of the value if any
+	switch {
+	case value.Type() == durationType:
+		return ros.Duration(value.Int()).String(), nil
+	case value.Type().Implements(textMarshalerType):
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	case value.Type().Implements(jsonMarshalerType):
+		data, err := value.Interface().(json.Marshaler).MarshalJSON()
+		return json.RawMessage(data), err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return ros.Bool(value.Bool()).String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, err := encodePayloadValue(indirect(value.Index(i)), false)
+			if err != nil {
+				return nil, err
+			}
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported list item type %s", value.Index(i).Type())
+			}
+			items = append(items, text)
+		}
+		if keepArray {
+			return items, nil
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", value.Type())
+}
+
+// indirect dereferences pointers and interfaces, returning an invalid value for nil
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// indirectType dereferences pointer types
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isNilValue checks if the value is invalid or a nil pointer, interface, map or slice
+func isNilValue(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return value.IsNil()
+	}
+	return false
+}
+
+// AddPayload encodes the payload with EncodePayload and creates a new PUT request, returning the result and error.
+func (c *Client) AddPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) {
+	body, err := EncodePayload(payload)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+	return c.Add(ctx, command, body)
+}
+
+// SetPayload encodes the payload with EncodePatchPayload and creates a new PATCH request, returning the result and error.
+func (c *Client) SetPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) {
+	body, err := EncodePatchPayload(payload)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+	return c.Set(ctx, command, body)
+}
+
+// RunPayload encodes the payload with EncodePayload and creates a new POST request, returning the result and error.
+func (c *Client) RunPayload(ctx context.Context, command string, payload interface{}) (interface{}, error) {
+	body, err := EncodePayload(payload)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+	return c.Run(ctx, command, body)
+}
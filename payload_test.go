@@ -0,0 +1,193 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumitroajiprabowo/routerosv7-restfull-api/ros"
+)
+
+// addressPayload is a typed ip/address payload used by the payload tests
+type addressPayload struct {
+	Address   string `ros:"address"`
+	Interface string `ros:"interface"`
+	Disabled  bool   `ros:"disabled"`
+	Comment   string `ros:"comment,omitempty"`
+	Internal  string `ros:"-"`
+}
+
+// TestEncodePayload_Struct tests encoding a struct with ros tags
+func TestEncodePayload_Struct(t *testing.T) {
+	payload, err := EncodePayload(addressPayload{
+		Address:   "192.168.99.1/24",
+		Interface: "ether1",
+		Internal:  "ignored",
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"address":"192.168.99.1/24","interface":"ether1","disabled":"no"}`, string(payload))
+}
+
+// TestEncodePayload_Escaping tests that values are escaped instead of corrupting the JSON payload
+func TestEncodePayload_Escaping(t *testing.T) {
+	payload, err := EncodePayload(map[string]interface{}{"comment": `say "hi" \ bye`})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"comment":"say \"hi\" \\ bye"}`, string(payload))
+}
+
+// TestEncodePayload_Values tests the RouterOS encoding of the supported value types
+func TestEncodePayload_Values(t *testing.T) {
+	yes := true
+	id, _ := ros.ParseID("*1A")
+
+	payload, err := EncodePayload(map[string]interface{}{
+		"disabled":      true,
+		"dynamic":       &yes,
+		"mtu":           1500,
+		"distance":      uint8(1),
+		"ratio":         0.5,
+		"timeout":       90 * time.Second,
+		"keepalive":     ros.Duration(10 * time.Second),
+		"numbers":       id,
+		"ports":         []string{"ether1", "ether2"},
+		".proplist":     []string{"address", "interface"},
+		"comment":       nil,
+		"mac-address":   ros.MAC(nil),
+		"address-lists": []int{1, 2},
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"disabled": "yes",
+		"dynamic": "yes",
+		"mtu": "1500",
+		"distance": "1",
+		"ratio": "0.5",
+		"timeout": "1m30s",
+		"keepalive": "10s",
+		"numbers": "*1A",
+		"ports": "ether1,ether2",
+		".proplist": ["address", "interface"],
+		"address-lists": "1,2"
+	}`, string(payload))
+}
+
+// TestEncodePatchPayload tests that zero values are omitted from PATCH payloads unless set through a pointer
+func TestEncodePatchPayload(t *testing.T) {
+	type patch struct {
+		Comment  string `ros:"comment"`
+		Disabled *bool  `ros:"disabled"`
+		MTU      int    `ros:"mtu"`
+	}
+	no := false
+
+	payload, err := EncodePatchPayload(patch{Comment: "uplink", Disabled: &no})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"comment":"uplink","disabled":"no"}`, string(payload))
+}
+
+// TestEncodePayload_Embedded tests that embedded structs are flattened
+func TestEncodePayload_Embedded(t *testing.T) {
+	type common struct {
+		Comment string `ros:"comment"`
+	}
+	type withCommon struct {
+		common
+		Name string `ros:"name"`
+	}
+
+	payload, err := EncodePayload(&withCommon{common: common{Comment: "c"}, Name: "n"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"comment":"c","name":"n"}`, string(payload))
+}
+
+// TestEncodePayload_Invalid tests the payloads rejected before the request is sent
+func TestEncodePayload_Invalid(t *testing.T) {
+	type untagged struct {
+		Address string
+	}
+	type emptyName struct {
+		Address string `ros:",omitempty"`
+	}
+	type duplicate struct {
+		A string `ros:"name"`
+		B string `ros:"name"`
+	}
+	type nested struct {
+		Inner struct{} `ros:"inner"`
+	}
+
+	tests := []struct {
+		name    string      // Test case name
+		payload interface{} // Payload under test
+	}{
+		{"Nil payload", nil},
+		{"Unsupported type", "address=1"},
+		{"Unsupported map key", map[int]string{1: "a"}},
+		{"Empty key", map[string]string{"": "a"}},
+		{"Upper case key", map[string]string{"Address": "a"}},
+		{"Key with space", map[string]string{"dst address": "a"}},
+		{"Unknown special field", map[string]string{".unknown": "a"}},
+		{"Untagged field", untagged{Address: "a"}},
+		{"Empty tag name", emptyName{Address: "a"}},
+		{"Duplicate field", duplicate{A: "a", B: "b"}},
+		{"Nested struct", nested{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EncodePayload(tt.payload)
+			assert.Error(t, err)
+		})
+	}
+
+	// Field errors are returned as *PayloadError
+	_, err := EncodePayload(map[string]string{"Address": "a"})
+	var payloadErr *PayloadError
+	require.True(t, errors.As(err, &payloadErr))
+	assert.Equal(t, "Address", payloadErr.Field)
+}
+
+// TestClient_PayloadVerbs tests that the payload verbs send the encoded payload
+func TestClient_PayloadVerbs(t *testing.T) {
+	server, requests := setupRecordingServer(t, `{}`)
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	payload := addressPayload{Address: "192.168.99.1/24", Interface: "ether1"}
+
+	_, err = client.AddPayload(ctx, "ip/address", payload)
+	require.NoError(t, err)
+	_, err = client.SetPayload(ctx, "ip/address/*1", payload)
+	require.NoError(t, err)
+	_, err = client.RunPayload(ctx, "ip/address/print", map[string]interface{}{".proplist": []string{"address"}})
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 3)
+	assert.Equal(t, http.MethodPut, (*requests)[0].Method)
+	assert.JSONEq(t, `{"address":"192.168.99.1/24","interface":"ether1","disabled":"no"}`, (*requests)[0].Body)
+	assert.Equal(t, http.MethodPatch, (*requests)[1].Method)
+	assert.JSONEq(t, `{"address":"192.168.99.1/24","interface":"ether1"}`, (*requests)[1].Body)
+	assert.Equal(t, http.MethodPost, (*requests)[2].Method)
+	assert.JSONEq(t, `{".proplist":["address"]}`, (*requests)[2].Body)
+
+	// An invalid payload is rejected before any request is sent
+	_, err = client.AddPayload(ctx, "ip/address", map[string]string{"": "a"})
+	assert.Error(t, err)
+	_, err = client.SetPayload(ctx, "ip/address/*1", 42)
+	assert.Error(t, err)
+	_, err = client.RunPayload(ctx, "ip/address/print", nil)
+	assert.Error(t, err)
+	assert.Len(t, *requests, 3)
+}
@@ -1,27 +1,53 @@
 package routerosv7_restfull_api
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"net"
 	"strings"
 )
 
 /*
-determineProtocol determines the protocol to use for the request.
-If the host is available on port 443, HTTPS is used, if not, then HTTP is used.
+determineProtocol determines the protocol to use for the host by probing its ports.
+If the host is available on the HTTPS port, HTTPS is used, if not, then HTTP is used if the host is available
+on the HTTP port. Both ports are probed at once, so that an HTTPS port silently dropping the packets cannot use
+the whole deadline before the HTTP port is probed. An error is returned if neither port is available before the
+context is done. The probes are logged with the logger at the Debug level.
 */
-func determineProtocol(host string) string {
-	if isHostAvailableOnPort(host, "443") {
-		return httpsProtocol
+func determineProtocol(ctx context.Context, logger *slog.Logger, host, httpsPort, httpPort string) (string, error) {
+	httpsAvailable := probePort(ctx, logger, host, httpsPort)
+	httpAvailable := probePort(ctx, logger, host, httpPort)
+
+	// HTTPS is preferred when both ports are available
+	if <-httpsAvailable {
+		return httpsProtocol, nil
 	}
-	return httpProtocol
+	if <-httpAvailable {
+		return httpProtocol, nil
+	}
+
+	// Check if the probes were cut short by the context
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("determineProtocol: %s: %w", host, err)
+	}
+	return "", fmt.Errorf("determineProtocol: %s is not available on port %s or %s", host, httpsPort, httpPort)
+}
+
+// probePort checks in the background if the host is available on the port, the result is sent on the channel
+func probePort(ctx context.Context, logger *slog.Logger, host, port string) <-chan bool {
+	available := make(chan bool, 1)
+	go func() {
+		available <- isHostAvailableOnPort(ctx, logger, host, port)
+	}()
+	return available
 }
 
-// closeConnection closes a connection.
-func closeConnection(conn net.Conn) {
+// closeConnection closes a connection and logs the error with the logger if any.
+func closeConnection(logger *slog.Logger, conn net.Conn) {
 	err := conn.Close()
 	if err != nil {
-		log.Println(err)
+		loggerOrDiscard(logger).Warn("routeros: closing the probe connection failed", slog.Any("error", err))
 	}
 }
 
@@ -43,12 +69,17 @@ func shouldRetryTlsErrorRequest(err error, protocol string) bool {
 	return strings.Contains(err.Error(), tlsHandshakeFailure) && protocol == httpsProtocol
 }
 
-// IsHostAvailableOnPort checks if a host is available on a given port.
-func isHostAvailableOnPort(host, port string) bool {
-	conn, err := net.Dial("tcp", host+":"+port)
+// dialProbe opens the probe connections, replaced in tests
+var dialProbe = (&net.Dialer{}).DialContext
+
+// isHostAvailableOnPort checks if a host accepts TCP connections on a given port before the context is done.
+func isHostAvailableOnPort(ctx context.Context, logger *slog.Logger, host, port string) bool {
+	conn, err := dialProbe(ctx, "tcp", net.JoinHostPort(host, port))
+	loggerOrDiscard(logger).DebugContext(ctx, "routeros probe", slog.String("host", host), slog.String("port", port),
+		slog.Bool("available", err == nil))
 	if err != nil {
 		return false
 	}
-	defer closeConnection(conn)
+	defer closeConnection(logger, conn)
 	return true
 }
@@ -0,0 +1,119 @@
+package routerosv7_restfull_api
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of ProtocolAutoDetect
+const (
+	DefaultProtocolCacheTTL = 10 * time.Minute // DefaultProtocolCacheTTL is the TTL of the shared protocol cache
+	DefaultProbeTimeout     = 3 * time.Second  // DefaultProbeTimeout is the deadline for probing the ports of a router
+)
+
+// defaultProtocolCache is the cache shared by the clients created without WithProtocolCache
+var defaultProtocolCache = NewProtocolCache(DefaultProtocolCacheTTL)
+
+/*
+ProtocolCache caches the protocol detected for each router by ProtocolAutoDetect, so that the ports of a router are
+probed once per TTL instead of before every request. Entries are keyed by host and probe ports, so that clients
+probing other ports of the same host do not share the detected protocol. A ProtocolCache is safe for concurrent
+use by multiple clients.
+*/
+type ProtocolCache struct {
+	ttl     time.Duration                           // Time to live of the entries
+	now     func() time.Time                        // Clock, replaced in tests
+	mu      sync.Mutex                              // Mutex protecting the entries
+	entries map[protocolCacheKey]protocolCacheEntry // Entries by host and probe ports
+}
+
+// protocolCacheKey is the host of a router and the ports probed on it
+type protocolCacheKey struct {
+	host      string // Host of the router
+	httpsPort string // HTTPS port probed on the host
+	httpPort  string // HTTP port probed on the host
+}
+
+// protocolCacheEntry is a protocol detected for a host and its expiration time
+type protocolCacheEntry struct {
+	protocol string    // Detected protocol, "http" or "https"
+	expires  time.Time // Time after which the protocol is probed again
+}
+
+// NewProtocolCache creates a protocol cache whose entries expire after the TTL
+func NewProtocolCache(ttl time.Duration) *ProtocolCache {
+	return &ProtocolCache{ttl: ttl, now: time.Now, entries: make(map[protocolCacheKey]protocolCacheEntry)}
+}
+
+// Get returns the protocol cached for the host probed on the ports, if any and not expired
+func (c *ProtocolCache) Get(host, httpsPort, httpPort string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := protocolCacheKey{host: host, httpsPort: httpsPort, httpPort: httpPort}
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.protocol, true
+}
+
+// Set caches the protocol for the host probed on the ports for the TTL of the cache
+func (c *ProtocolCache) Set(host, httpsPort, httpPort, protocol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := protocolCacheKey{host: host, httpsPort: httpsPort, httpPort: httpPort}
+	c.entries[key] = protocolCacheEntry{protocol: protocol, expires: c.now().Add(c.ttl)}
+}
+
+/*
+Invalidate removes the protocol cached for the host probed on the ports, which is probed again before the next
+request
+*/
+func (c *ProtocolCache) Invalidate(host, httpsPort, httpPort string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, protocolCacheKey{host: host, httpsPort: httpsPort, httpPort: httpPort})
+}
+
+// WithProtocolCache sets the cache used by ProtocolAutoDetect instead of the cache shared by every client
+func WithProtocolCache(cache *ProtocolCache) Option {
+	return func(o *clientOptions) error {
+		// Check if the cache is nil
+		if cache == nil {
+			return errors.New("WithProtocolCache: nil cache")
+		}
+		o.protocolCache = cache // Set the cache
+		return nil
+	}
+}
+
+// WithProbePorts sets the HTTPS and HTTP ports probed by ProtocolAutoDetect, 443 and 80 by default
+func WithProbePorts(httpsPort, httpPort int) Option {
+	return func(o *clientOptions) error {
+		// Check if the ports are valid
+		if httpsPort < 1 || httpsPort > 65535 || httpPort < 1 || httpPort > 65535 {
+			return errors.New("WithProbePorts: ports must be between 1 and 65535")
+		}
+		o.httpsPort = strconv.Itoa(httpsPort) // Set the HTTPS port
+		o.httpPort = strconv.Itoa(httpPort)   // Set the HTTP port
+		return nil
+	}
+}
+
+// WithProbeTimeout sets the deadline for probing the ports of the router, DefaultProbeTimeout by default
+func WithProbeTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) error {
+		// Check if the timeout is positive
+		if timeout <= 0 {
+			return errors.New("WithProbeTimeout: timeout must be positive")
+		}
+		o.probeTimeout = timeout // Set the timeout
+		return nil
+	}
+}
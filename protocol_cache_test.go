@@ -0,0 +1,198 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serverPort returns the port of a test server as an int
+func serverPort(t *testing.T, address string) int {
+	_, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	number, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return number
+}
+
+// TestProtocolCache tests the expiration and the invalidation of the cache entries
+func TestProtocolCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewProtocolCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	_, ok := cache.Get("router.lan", "443", "80")
+	assert.False(t, ok)
+
+	cache.Set("router.lan", "443", "80", httpsProtocol)
+	protocol, ok := cache.Get("router.lan", "443", "80")
+	assert.True(t, ok)
+	assert.Equal(t, httpsProtocol, protocol)
+
+	// The entries are kept per probe ports
+	_, ok = cache.Get("router.lan", "8443", "80")
+	assert.False(t, ok)
+	_, ok = cache.Get("router.lan", "443", "8080")
+	assert.False(t, ok)
+
+	// The entry expires after the TTL
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("router.lan", "443", "80")
+	assert.False(t, ok)
+
+	// Invalidating removes the entry
+	cache.Set("router.lan", "443", "80", httpProtocol)
+	cache.Invalidate("router.lan", "443", "80")
+	_, ok = cache.Get("router.lan", "443", "80")
+	assert.False(t, ok)
+}
+
+// TestProtocolAutoDetect_HTTP tests that a router only serving HTTP is detected and that the result is cached
+func TestProtocolAutoDetect_HTTP(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+	cache := NewProtocolCache(time.Minute)
+	httpsPort, httpPort := serverPort(t, closedLocalAddress(t)), serverPort(t, serverHost(server))
+
+	client, err := NewClient("127.0.0.1",
+		WithProtocolPolicy(ProtocolAutoDetect),
+		WithProtocolCache(cache),
+		WithProbePorts(httpsPort, httpPort),
+	)
+	require.NoError(t, err)
+
+	protocol, err := client.Protocol(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, httpProtocol, protocol)
+
+	cached, ok := cache.Get("127.0.0.1", strconv.Itoa(httpsPort), strconv.Itoa(httpPort))
+	assert.True(t, ok)
+	assert.Equal(t, httpProtocol, cached)
+
+	// The request is sent to the detected port
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Len(t, *requests, 1)
+}
+
+// TestProtocolAutoDetect_HTTPS tests that HTTPS is preferred when the router serves it
+func TestProtocolAutoDetect_HTTPS(t *testing.T) {
+	server := setupTLSServer(t, nil)
+
+	client, err := NewClient("127.0.0.1",
+		WithProtocolPolicy(ProtocolAutoDetect),
+		WithProtocolCache(NewProtocolCache(time.Minute)),
+		WithProbePorts(serverPort(t, server.Listener.Addr().String()), serverPort(t, closedLocalAddress(t))),
+		WithRootCAsPEM(serverCertificatePEM(server)),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	protocol, err := client.Protocol(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, httpsProtocol, protocol)
+}
+
+// TestProtocolAutoDetect_SharedCache tests that clients probing other ports of the same host do not share the protocol
+func TestProtocolAutoDetect_SharedCache(t *testing.T) {
+	httpServer, _ := setupRecordingServer(t, `[]`)
+	tlsServer := setupTLSServer(t, nil)
+	cache := NewProtocolCache(time.Minute)
+
+	httpClient, err := NewClient("127.0.0.1",
+		WithProtocolPolicy(ProtocolAutoDetect),
+		WithProtocolCache(cache),
+		WithProbePorts(serverPort(t, closedLocalAddress(t)), serverPort(t, serverHost(httpServer))),
+	)
+	require.NoError(t, err)
+
+	httpsClient, err := NewClient("127.0.0.1",
+		WithProtocolPolicy(ProtocolAutoDetect),
+		WithProtocolCache(cache),
+		WithProbePorts(serverPort(t, tlsServer.Listener.Addr().String()), serverPort(t, closedLocalAddress(t))),
+	)
+	require.NoError(t, err)
+
+	protocol, err := httpClient.Protocol(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, httpProtocol, protocol)
+
+	protocol, err = httpsClient.Protocol(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, httpsProtocol, protocol)
+}
+
+// TestProtocolAutoDetect_InvalidatedOnConnectionError tests that a connection error removes the cached protocol
+func TestProtocolAutoDetect_InvalidatedOnConnectionError(t *testing.T) {
+	server, _ := setupRecordingServer(t, `[]`)
+	cache := NewProtocolCache(time.Minute)
+	httpsPort, httpPort := serverPort(t, closedLocalAddress(t)), serverPort(t, serverHost(server))
+
+	client, err := NewClient("127.0.0.1",
+		WithProtocolPolicy(ProtocolAutoDetect),
+		WithProtocolCache(cache),
+		WithProbePorts(httpsPort, httpPort),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	// The router goes away
+	server.Close()
+
+	_, err = client.Print(context.Background(), "ip/address")
+	var transportErr *TransportError
+	assert.True(t, errors.As(err, &transportErr), "error: %v", err)
+
+	_, ok := cache.Get("127.0.0.1", strconv.Itoa(httpsPort), strconv.Itoa(httpPort))
+	assert.False(t, ok)
+
+	// The next request probes again and fails before sending anything
+	_, err = PrintAs[[]map[string]string](context.Background(), client, "ip/address")
+	assert.ErrorContains(t, err, "is not available on port")
+}
+
+// TestProtocolAutoDetect_ProbeTimeout tests that the probes are cut short by the context
+func TestProtocolAutoDetect_ProbeTimeout(t *testing.T) {
+	client, err := NewClient("127.0.0.1",
+		WithProtocolPolicy(ProtocolAutoDetect),
+		WithProtocolCache(NewProtocolCache(time.Minute)),
+		WithProbeTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = client.Protocol(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestProtocolAutoDetect_InvalidOptions tests the options rejected with ProtocolAutoDetect
+func TestProtocolAutoDetect_InvalidOptions(t *testing.T) {
+	autoDetect := WithProtocolPolicy(ProtocolAutoDetect)
+
+	_, err := NewClient("https://router.lan", autoDetect)
+	assert.Error(t, err)
+	_, err = NewClient("router.lan:8080", autoDetect)
+	assert.Error(t, err)
+	_, err = NewClient("router.lan", autoDetect, WithProtocolCache(nil))
+	assert.EqualError(t, err, "WithProtocolCache: nil cache")
+	_, err = NewClient("router.lan", autoDetect, WithProbePorts(0, 80))
+	assert.Error(t, err)
+	_, err = NewClient("router.lan", autoDetect, WithProbeTimeout(0))
+	assert.Error(t, err)
+}
+
+// closedLocalAddress returns a local address with no listener
+func closedLocalAddress(t *testing.T) string {
+	return net.JoinHostPort("127.0.0.1", closedLocalPort(t))
+}
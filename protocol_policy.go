@@ -0,0 +1,112 @@
+package routerosv7_restfull_api
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// ProtocolPolicy decides which protocol a Client uses and whether it may fall back from HTTPS to HTTP
+type ProtocolPolicy int
+
+const (
+	// ProtocolFromEndpoint uses the scheme of the endpoint and never downgrades, it is the default policy
+	ProtocolFromEndpoint ProtocolPolicy = iota
+	// HTTPSOnly always uses HTTPS and never downgrades
+	HTTPSOnly
+	// HTTPOnly always uses plain HTTP
+	HTTPOnly
+	/*
+		PreferHTTPSWithFallback uses HTTPS and resends a request over plain HTTP, including its credentials,
+		when the TLS handshake fails. Every downgrade is logged and reported to the hook set with WithDowngradeHook.
+	*/
+	PreferHTTPSWithFallback
+	/*
+		ProtocolAutoDetect probes the HTTPS and HTTP ports of the router, 443 and 80 unless set with WithProbePorts,
+		and uses HTTPS if available or HTTP otherwise. The result is cached per router for the TTL of the
+		protocol cache and invalidated on connection errors, see ProtocolCache and Client.Protocol.
+	*/
+	ProtocolAutoDetect
+)
+
+// String returns the name of the policy
+func (p ProtocolPolicy) String() string {
+	switch p {
+	case ProtocolFromEndpoint:
+		return "ProtocolFromEndpoint"
+	case HTTPSOnly:
+		return "HTTPSOnly"
+	case HTTPOnly:
+		return "HTTPOnly"
+	case PreferHTTPSWithFallback:
+		return "PreferHTTPSWithFallback"
+	case ProtocolAutoDetect:
+		return "ProtocolAutoDetect"
+	}
+	return fmt.Sprintf("ProtocolPolicy(%d)", int(p))
+}
+
+// DowngradeEvent describes a request resent over plain HTTP after a failed TLS handshake
+type DowngradeEvent struct {
+	Method      string // HTTP method of the request
+	URL         string // HTTPS URL of the failed request
+	FallbackURL string // HTTP URL the request is resent to
+	Err         error  // Error of the failed TLS handshake
+}
+
+// WithProtocolPolicy sets the protocol policy of the client, see ProtocolPolicy
+func WithProtocolPolicy(policy ProtocolPolicy) Option {
+	return func(o *clientOptions) error {
+		// Check if the policy is known
+		if policy < ProtocolFromEndpoint || policy > ProtocolAutoDetect {
+			return fmt.Errorf("WithProtocolPolicy: unknown policy %s", policy)
+		}
+		o.protocolPolicy = policy // Set the policy
+		return nil
+	}
+}
+
+// WithDowngradeHook sets a function called before a request is resent over plain HTTP by PreferHTTPSWithFallback
+func WithDowngradeHook(hook func(DowngradeEvent)) Option {
+	return func(o *clientOptions) error {
+		o.downgradeHook = hook // Set the hook
+		return nil
+	}
+}
+
+// applyProtocolPolicy returns the endpoint with the scheme required by the policy
+func applyProtocolPolicy(endpoint Endpoint, policy ProtocolPolicy) (Endpoint, error) {
+	switch policy {
+	case HTTPSOnly, PreferHTTPSWithFallback:
+		// Check if the endpoint explicitly requires plain HTTP
+		if endpoint.Scheme == httpProtocol {
+			return Endpoint{}, fmt.Errorf("NewClient: %s conflicts with the http scheme of the endpoint", policy)
+		}
+		return endpoint.WithScheme(httpsProtocol), nil
+	case HTTPOnly:
+		// Check if the endpoint explicitly requires HTTPS
+		if endpoint.Scheme == httpsProtocol {
+			return Endpoint{}, fmt.Errorf("NewClient: %s conflicts with the https scheme of the endpoint", policy)
+		}
+		return endpoint.WithScheme(httpProtocol), nil
+	case ProtocolAutoDetect:
+		// Check if the endpoint leaves the protocol and the port to the detection
+		if endpoint.Scheme != "" || endpoint.Port != "" {
+			return Endpoint{}, fmt.Errorf("NewClient: %s conflicts with the scheme or port of the endpoint, "+
+				"use WithProbePorts to set the probed ports", policy)
+		}
+	}
+	return endpoint, nil
+}
+
+/*
+notifyDowngrade logs the downgrade with the logger of the request configuration at the Warn level and calls
+the downgrade hook of the request configuration if any
+*/
+func notifyDowngrade(config requestConfig, event DowngradeEvent) {
+	loggerOrDiscard(config.Logger).Warn("routeros: TLS handshake failed, resending the request over plain HTTP",
+		slog.String("method", event.Method), slog.String("url", event.URL),
+		slog.String("fallback_url", event.FallbackURL), slog.Any("error", event.Err))
+	if config.OnDowngrade != nil {
+		config.OnDowngrade(event)
+	}
+}
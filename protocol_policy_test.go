@@ -0,0 +1,123 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshakeFailureTransport fails every HTTPS request with a TLS handshake failure and sends HTTP requests
+type handshakeFailureTransport struct {
+	schemes []string // Schemes of the requests received by the transport
+}
+
+// RoundTrip fails HTTPS requests and sends HTTP requests with the default transport
+func (h *handshakeFailureTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	h.schemes = append(h.schemes, request.URL.Scheme)
+	if request.URL.Scheme == httpsProtocol {
+		return nil, errors.New("remote error: tls: handshake failure")
+	}
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// TestProtocolPolicy_NoDowngradeByDefault tests that a failed TLS handshake is never resent over HTTP by default
+func TestProtocolPolicy_NoDowngradeByDefault(t *testing.T) {
+	for _, policy := range []ProtocolPolicy{ProtocolFromEndpoint, HTTPSOnly} {
+		t.Run(policy.String(), func(t *testing.T) {
+			server, requests := setupRecordingServer(t, `[]`)
+			transport := &handshakeFailureTransport{}
+			called := false
+
+			client, err := NewClient("https://"+serverHost(server),
+				WithCredentials("admin", "secret"),
+				WithTransport(transport),
+				WithProtocolPolicy(policy),
+				WithDowngradeHook(func(DowngradeEvent) { called = true }),
+			)
+			require.NoError(t, err)
+
+			_, err = client.Print(context.Background(), "ip/address")
+
+			var tlsErr *TLSError
+			assert.True(t, errors.As(err, &tlsErr), "error: %v", err)
+			assert.Equal(t, []string{httpsProtocol}, transport.schemes)
+			assert.Empty(t, *requests, "credentials must not be sent over plain HTTP")
+			assert.False(t, called)
+		})
+	}
+}
+
+// TestProtocolPolicy_PreferHTTPSWithFallback tests that the downgrade is performed and reported when enabled
+func TestProtocolPolicy_PreferHTTPSWithFallback(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+	transport := &handshakeFailureTransport{}
+	var events []DowngradeEvent
+
+	client, err := NewClient(serverHost(server),
+		WithCredentials("admin", "secret"),
+		WithTransport(transport),
+		WithProtocolPolicy(PreferHTTPSWithFallback),
+		WithDowngradeHook(func(event DowngradeEvent) { events = append(events, event) }),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, httpsProtocol, client.Endpoint().Scheme)
+
+	_, err = client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	// The request was resent over HTTP
+	assert.Equal(t, []string{httpsProtocol, httpProtocol}, transport.schemes)
+	require.Len(t, *requests, 1)
+	assert.Equal(t, "admin", (*requests)[0].Username)
+
+	// The downgrade was reported
+	require.Len(t, events, 1)
+	assert.Equal(t, MethodGet, events[0].Method)
+	assert.Equal(t, "https://"+serverHost(server)+"/rest/ip/address", events[0].URL)
+	assert.Equal(t, "http://"+serverHost(server)+"/rest/ip/address", events[0].FallbackURL)
+	assert.ErrorContains(t, events[0].Err, "tls: handshake failure")
+}
+
+// TestProtocolPolicy_Scheme tests the scheme chosen by each policy
+func TestProtocolPolicy_Scheme(t *testing.T) {
+	tests := []struct {
+		name     string         // Test case name
+		host     string         // Host given to NewClient
+		policy   ProtocolPolicy // Policy under test
+		expected string         // Expected scheme of the endpoint, empty for an error
+	}{
+		{"From endpoint without scheme", "router.lan", ProtocolFromEndpoint, ""},
+		{"From endpoint with https", "https://router.lan", ProtocolFromEndpoint, httpsProtocol},
+		{"HTTPS only", "router.lan", HTTPSOnly, httpsProtocol},
+		{"HTTPS only with https", "https://router.lan", HTTPSOnly, httpsProtocol},
+		{"HTTP only", "router.lan:443", HTTPOnly, httpProtocol},
+		{"Fallback", "router.lan", PreferHTTPSWithFallback, httpsProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.host, WithProtocolPolicy(tt.policy))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, client.Endpoint().Scheme)
+		})
+	}
+}
+
+// TestProtocolPolicy_Conflicts tests that a policy conflicting with the scheme of the endpoint is rejected
+func TestProtocolPolicy_Conflicts(t *testing.T) {
+	_, err := NewClient("http://router.lan", WithProtocolPolicy(HTTPSOnly))
+	assert.EqualError(t, err, "NewClient: HTTPSOnly conflicts with the http scheme of the endpoint")
+
+	_, err = NewClient("router.lan", WithScheme("http"), WithProtocolPolicy(PreferHTTPSWithFallback))
+	assert.Error(t, err)
+
+	_, err = NewClient("https://router.lan", WithProtocolPolicy(HTTPOnly))
+	assert.EqualError(t, err, "NewClient: HTTPOnly conflicts with the https scheme of the endpoint")
+
+	_, err = NewClient("router.lan", WithProtocolPolicy(ProtocolPolicy(42)))
+	assert.EqualError(t, err, "WithProtocolPolicy: unknown policy ProtocolPolicy(42)")
+}
@@ -5,8 +5,12 @@ This is unittest for protocol.go
 package routerosv7_restfull_api
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log/slog"
 	"net"
+	"strings"
 	"testing"
 	"time"
 )
@@ -60,7 +64,7 @@ func TestIsHostAvailableOnPort_NotAvailable(t *testing.T) {
 	defer func() {}()
 
 	// Use a non-existent port for testing
-	available := isHostAvailableOnPort("localhost", "9999")
+	available := isHostAvailableOnPort(context.Background(), discardLogger, "localhost", "9999")
 	if available {
 		t.Error("Expected host to be not available, got true")
 	}
@@ -90,7 +94,7 @@ func TestIsHostAvailableOnPort_Available(t *testing.T) {
 	defer func() {}()
 
 	// Use an existing port for testing
-	available := isHostAvailableOnPort("localhost", "8080")
+	available := isHostAvailableOnPort(context.Background(), discardLogger, "localhost", "8080")
 	if !available {
 		t.Error("Expected host to be available, got false")
 	}
@@ -112,15 +116,41 @@ func TestShouldRetryRequest_TLSHandshakeFailure_HTTP(t *testing.T) {
 	}
 }
 
+// listenLocalPort creates a listener on a free local port and returns the port
+func listenLocalPort(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create a listener: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+// closedLocalPort returns a local port with no listener
+func closedLocalPort(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create a listener: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	_ = listener.Close()
+	return port
+}
+
 /*
-TestShouldRetryRequest_TLSHandshakeFailure_HTTPS tests the shouldRetryTlsErrorRequest function.
-It is not possible to test the actual connection to the host, if https is not available.
-This is because the URL is not parsed in the function.
+TestDetermineProtocol_HTTP tests the determineProtocol function.
+The host is only available on the HTTP port, so HTTP is used.
 */
 func TestDetermineProtocol_HTTP(t *testing.T) {
 
-	// Assuming the host is not actually available on port 80 in the test environment
-	protocol := determineProtocol("example.com:80")
+	// Only the HTTP port is available
+	protocol, err := determineProtocol(context.Background(), discardLogger, "127.0.0.1",
+		closedLocalPort(t), listenLocalPort(t))
+	if err != nil {
+		t.Fatalf("determineProtocol failed: %v", err)
+	}
 
 	// Test the actual connection to the host
 	if protocol != httpProtocol {
@@ -130,13 +160,16 @@ func TestDetermineProtocol_HTTP(t *testing.T) {
 
 /*
 TestDetermineProtocol_HTTPS tests the determineProtocol function.
-It is not possible to test the actual connection to the host, if https is not available.
-This is because the URL is not parsed in the function.
+The host is available on the HTTPS port, so HTTPS is used even if the HTTP port is available too.
 */
 func TestDetermineProtocol_HTTPS(t *testing.T) {
 
-	// Assuming the host is not actually available on port 443 in the test environment
-	protocol := determineProtocol("example.com")
+	// Both ports are available
+	protocol, err := determineProtocol(context.Background(), discardLogger, "127.0.0.1",
+		listenLocalPort(t), listenLocalPort(t))
+	if err != nil {
+		t.Fatalf("determineProtocol failed: %v", err)
+	}
 
 	// Test the actual connection to the host
 	if protocol != httpsProtocol {
@@ -144,6 +177,56 @@ func TestDetermineProtocol_HTTPS(t *testing.T) {
 	}
 }
 
+// TestDetermineProtocol_Unavailable tests the determineProtocol function when no port is available.
+func TestDetermineProtocol_Unavailable(t *testing.T) {
+	_, err := determineProtocol(context.Background(), discardLogger, "127.0.0.1",
+		closedLocalPort(t), closedLocalPort(t))
+	if err == nil {
+		t.Error("Expected an error, got nil")
+	}
+}
+
+// TestDetermineProtocol_ContextDone tests that the probes stop when the context is done.
+func TestDetermineProtocol_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := determineProtocol(ctx, discardLogger, "127.0.0.1", listenLocalPort(t), listenLocalPort(t))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+/*
+TestDetermineProtocol_SilentHTTPSPort tests the determineProtocol function when the HTTPS port drops the packets.
+The HTTP port is probed at the same time, so HTTP is used once the deadline cuts the HTTPS probe short.
+*/
+func TestDetermineProtocol_SilentHTTPSPort(t *testing.T) {
+	httpsPort, httpPort := closedLocalPort(t), listenLocalPort(t)
+
+	// The dial to the HTTPS port never answers
+	defaultDial := dialProbe
+	dialProbe = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address == net.JoinHostPort("127.0.0.1", httpsPort) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return defaultDial(ctx, network, address)
+	}
+	defer func() { dialProbe = defaultDial }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	protocol, err := determineProtocol(ctx, discardLogger, "127.0.0.1", httpsPort, httpPort)
+	if err != nil {
+		t.Fatalf("determineProtocol failed: %v", err)
+	}
+	if protocol != httpProtocol {
+		t.Errorf("Expected HTTP protocol, got %s", protocol)
+	}
+}
+
 /*
 TestCloseResponseBody tests the closeResponseBody function.
 It is not possible to test the actual closing of the response body,
@@ -154,8 +237,17 @@ func TestCloseConnection(t *testing.T) {
 	// Test closing the response body
 	mockConn := &mockErrorConn{}
 
+	// Capture the log output
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
 	// Test closing the response body
-	closeConnection(mockConn)
+	closeConnection(logger, mockConn)
+
+	// Check if the error is logged
+	if !strings.Contains(output.String(), "closing the probe connection failed") {
+		t.Errorf("Expected the close error to be logged, got %q", output.String())
+	}
 }
 
 /*
@@ -0,0 +1,298 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// queryNamePattern matches the property names usable in a query, including dot properties such as ".id"
+var queryNamePattern = regexp.MustCompile(`^\.?[a-z0-9][a-z0-9-]*$`)
+
+// QueryError is returned when a query word is invalid or the stack operators are unbalanced
+type QueryError struct {
+	Index  int    // Index of the offending word in the .query stack, -1 for the whole query
+	Word   string // Offending word
+	Reason string // Reason why the word was rejected
+}
+
+// Error returns the offending word and the reason why it was rejected
+func (e *QueryError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("query: %s", e.Reason)
+	}
+	return fmt.Sprintf("query: word %d %q: %s", e.Index, e.Word, e.Reason)
+}
+
+/*
+Query builds the .query stack and the .proplist of a RouterOS print command.
+Every condition pushes a value on the stack, Or and And combine the new condition with the top of the stack,
+Not negates the next condition, and the remaining values are ANDed together.
+example:
+
+	NewQuery().Where("network", "192.168.99.0").Or("network", "10.0.0.0").Not().Has("comment").
+		Proplist("address", "interface")
+
+compiles to
+
+	{".query": ["network=192.168.99.0", "network=10.0.0.0", "#|", "comment", "#!", "#&"],
+	 ".proplist": ["address", "interface"]}
+*/
+type Query struct {
+	words      []string // Words of the .query stack
+	proplist   []string // Properties returned by the print command
+	negateNext bool     // Whether the next condition is negated
+	err        error    // First error found while building the query
+}
+
+// NewQuery creates an empty Query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds a condition matching items whose property equals the value, e.g. "name=value"
+func (q *Query) Where(name, value string) *Query {
+	return q.condition("", name, "="+value)
+}
+
+// Less adds a condition matching items whose property is less than the value, e.g. "<mtu=1500"
+func (q *Query) Less(name, value string) *Query {
+	return q.condition("<", name, "="+value)
+}
+
+// Greater adds a condition matching items whose property is greater than the value, e.g. ">mtu=1500"
+func (q *Query) Greater(name, value string) *Query {
+	return q.condition(">", name, "="+value)
+}
+
+// Has adds a condition matching items that have the property, e.g. "comment"
+func (q *Query) Has(name string) *Query {
+	return q.condition("", name, "")
+}
+
+// Missing adds a condition matching items that do not have the property, e.g. "-comment"
+func (q *Query) Missing(name string) *Query {
+	return q.condition("-", name, "")
+}
+
+// Or adds a condition on the property equal to the value and ORs it with the top of the stack
+func (q *Query) Or(name, value string) *Query {
+	return q.Where(name, value).Op("|")
+}
+
+// And adds a condition on the property equal to the value and ANDs it with the top of the stack
+func (q *Query) And(name, value string) *Query {
+	return q.Where(name, value).Op("&")
+}
+
+// Not negates the next condition
+func (q *Query) Not() *Query {
+	q.negateNext = !q.negateNext
+	return q
+}
+
+/*
+Op appends raw stack operations such as "|" (OR), "&" (AND), "!" (NOT), "." (duplicate the top) or a digit
+(copy the nth value), e.g. Op("|!") ORs the two top values and negates the result
+*/
+func (q *Query) Op(ops string) *Query {
+	q.words = append(q.words, "#"+ops)
+	return q
+}
+
+// Raw appends raw .query words, which are checked by Validate
+func (q *Query) Raw(words ...string) *Query {
+	q.words = append(q.words, words...)
+	return q
+}
+
+// Proplist sets the properties returned by the print command
+func (q *Query) Proplist(fields ...string) *Query {
+	for _, field := range fields {
+		if err := validateQueryName(field); err != nil {
+			return q.fail(err)
+		}
+	}
+	q.proplist = append(q.proplist, fields...)
+	return q
+}
+
+// condition validates the property name and pushes the condition word made of the operator, the name and the value
+func (q *Query) condition(operator, name, value string) *Query {
+	if err := validateQueryName(name); err != nil {
+		return q.fail(err)
+	}
+	return q.push(operator + name + value)
+}
+
+// push pushes a condition word and negates it if Not was called before
+func (q *Query) push(word string) *Query {
+	q.words = append(q.words, word)
+	if q.negateNext {
+		q.words = append(q.words, "#!")
+		q.negateNext = false
+	}
+	return q
+}
+
+// fail records the first error found while building the query
+func (q *Query) fail(err error) *Query {
+	if q.err == nil {
+		q.err = err
+	}
+	return q
+}
+
+// validateQueryName checks that the name is a property name usable in a query
+func validateQueryName(name string) error {
+	if !queryNamePattern.MatchString(name) {
+		return &QueryError{Index: -1, Word: name, Reason: fmt.Sprintf("invalid property name %q", name)}
+	}
+	return nil
+}
+
+// Words returns a copy of the words of the .query stack
+func (q *Query) Words() []string {
+	return append([]string(nil), q.words...)
+}
+
+// Validate checks the words of the query and that the stack operators are balanced
+func (q *Query) Validate() error {
+
+	// Return the first error found while building the query
+	if q.err != nil {
+		return q.err
+	}
+
+	// Check if Not was called without a following condition
+	if q.negateNext {
+		return &QueryError{Index: -1, Reason: "Not without a following condition"}
+	}
+
+	_, err := queryStackDepth(q.words)
+	return err
+}
+
+/*
+queryStackDepth simulates the .query stack and returns the number of values left on it.
+It returns an error if an operator pops more values than available or if a word is malformed.
+*/
+func queryStackDepth(words []string) (int, error) {
+	depth := 0
+
+	for i, word := range words {
+
+		// Check if the word is a condition
+		if !strings.HasPrefix(word, "#") {
+			if reason := checkQueryCondition(word); reason != "" {
+				return 0, &QueryError{Index: i, Word: word, Reason: reason}
+			}
+			depth++
+			continue
+		}
+
+		// Apply every operation of the operator word
+		for _, op := range word[1:] {
+			switch {
+			case op == '|' || op == '&':
+				if depth < 2 {
+					return 0, &QueryError{Index: i, Word: word, Reason: fmt.Sprintf("%q needs two values", op)}
+				}
+				depth--
+			case op == '!':
+				if depth < 1 {
+					return 0, &QueryError{Index: i, Word: word, Reason: "\"!\" needs one value"}
+				}
+			case op == '.':
+				if depth < 1 {
+					return 0, &QueryError{Index: i, Word: word, Reason: "\".\" needs one value"}
+				}
+				depth++
+			case op >= '0' && op <= '9':
+				if int(op-'0') >= depth {
+					return 0, &QueryError{Index: i, Word: word, Reason: fmt.Sprintf("no value %c on the stack", op)}
+				}
+				depth++
+			default:
+				return 0, &QueryError{Index: i, Word: word, Reason: fmt.Sprintf("unknown operator %q", op)}
+			}
+		}
+	}
+
+	return depth, nil
+}
+
+/*
+checkQueryCondition checks that the word is a RouterOS condition: "name=value", "<name=value", ">name=value",
+"name" or "-name". It returns the reason why the word is invalid, or an empty string.
+*/
+func checkQueryCondition(word string) string {
+
+	// Split the word into its operator, property name and value
+	operator, rest := "", word
+	if rest != "" && strings.ContainsRune("-<>", rune(rest[0])) {
+		operator, rest = rest[:1], rest[1:]
+	}
+	name, _, hasValue := strings.Cut(rest, "=")
+
+	switch {
+	case !queryNamePattern.MatchString(name):
+		return "invalid property name"
+	case operator == "-" && hasValue:
+		return "missing property cannot have a value"
+	case (operator == "<" || operator == ">") && !hasValue:
+		return fmt.Sprintf("%q needs a value", operator)
+	}
+	return ""
+}
+
+// Fields returns the .query and .proplist fields of the print payload, folding the stack into a single value
+func (q *Query) Fields() (map[string]interface{}, error) {
+
+	// Validate the query
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+
+	// Add the .query field, ANDing the values left on the stack
+	if len(q.words) > 0 {
+		depth, _ := queryStackDepth(q.words)
+		words := q.Words()
+		if depth > 1 {
+			words = append(words, "#"+strings.Repeat("&", depth-1))
+		}
+		fields[".query"] = words
+	}
+
+	// Add the .proplist field
+	if len(q.proplist) > 0 {
+		fields[".proplist"] = append([]string(nil), q.proplist...)
+	}
+
+	return fields, nil
+}
+
+// Payload returns the JSON payload of the print command
+func (q *Query) Payload() ([]byte, error) {
+	fields, err := q.Fields()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+/*
+RunQuery compiles the query and runs it with a POST request on a print command such as "ip/address/print",
+returning the result and error
+*/
+func (c *Client) RunQuery(ctx context.Context, command string, query *Query) (interface{}, error) {
+	payload, err := query.Payload()
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+	return c.Run(ctx, command, payload)
+}
@@ -0,0 +1,140 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuery_Words tests the words pushed by the builder methods
+func TestQuery_Words(t *testing.T) {
+	q := NewQuery().
+		Where("network", "192.168.99.0").
+		Or("network", "10.0.0.0").
+		Not().Has("comment").
+		Missing("dynamic").
+		Less("mtu", "1500").
+		Greater("distance", "1").
+		And(".id", "*1")
+
+	assert.Equal(t, []string{
+		"network=192.168.99.0", "network=10.0.0.0", "#|",
+		"comment", "#!",
+		"-dynamic",
+		"<mtu=1500",
+		">distance=1",
+		".id=*1", "#&",
+	}, q.Words())
+	assert.NoError(t, q.Validate())
+}
+
+// TestQuery_Payload tests the payload compiled for the print command
+func TestQuery_Payload(t *testing.T) {
+	payload, err := NewQuery().
+		Where("network", "192.168.99.0").Or("network", "10.0.0.0").
+		Not().Has("comment").
+		Proplist("address", "interface").
+		Payload()
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		".query": ["network=192.168.99.0", "network=10.0.0.0", "#|", "comment", "#!", "#&"],
+		".proplist": ["address", "interface"]
+	}`, string(payload))
+}
+
+// TestQuery_PayloadSingleValue tests that a query leaving one value on the stack is not folded
+func TestQuery_PayloadSingleValue(t *testing.T) {
+	payload, err := NewQuery().Where("network", "192.168.99.0").Payload()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{".query": ["network=192.168.99.0"]}`, string(payload))
+
+	payload, err = NewQuery().Proplist("address").Payload()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{".proplist": ["address"]}`, string(payload))
+}
+
+// TestQuery_Validate tests the validation of the stack operators and the words
+func TestQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name    string // Test case name
+		query   *Query // Query under test
+		wantErr bool   // Expected error
+	}{
+		{"Empty", NewQuery(), false},
+		{"Legacy hash", NewQuery().Raw("network=192.168.99.0", "#"), false},
+		{"Balanced raw", NewQuery().Raw("a=1", "b=2", "c=3", "#||!"), false},
+		{"Duplicate", NewQuery().Raw("a=1", "#.", "#&"), false},
+		{"Copy", NewQuery().Raw("a=1", "b=2", "#0", "#&&"), false},
+		{"Or without values", NewQuery().Op("|"), true},
+		{"Or with one value", NewQuery().Where("a", "1").Op("|"), true},
+		{"Not without value", NewQuery().Op("!"), true},
+		{"Duplicate without value", NewQuery().Op("."), true},
+		{"Copy out of range", NewQuery().Where("a", "1").Op("1"), true},
+		{"Unknown operator", NewQuery().Where("a", "1").Op("?"), true},
+		{"Dangling Not", NewQuery().Where("a", "1").Not(), true},
+		{"Invalid name", NewQuery().Where("A B", "1"), true},
+		{"Empty name", NewQuery().Has(""), true},
+		{"Invalid missing name", NewQuery().Missing("a=1"), true},
+		{"Invalid proplist", NewQuery().Proplist("address,interface"), true},
+		{"Invalid raw word", NewQuery().Raw("=1"), true},
+		{"Raw missing with value", NewQuery().Raw("-a=1"), true},
+		{"Raw comparisons", NewQuery().Raw("<mtu=1500", ">mtu=1000", "comment=a<b"), false},
+		{"Comparison after the name", NewQuery().Raw("mtu<1500"), true},
+		{"Comparison without value", NewQuery().Raw("<mtu"), true},
+		{"Regular expression", NewQuery().Raw("comment~^lab"), true},
+		{"Invalid less name", NewQuery().Less("A", "1"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+
+			// Invalid queries cannot be compiled
+			_, err = tt.query.Payload()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+// TestQuery_ValidateError tests that the error points to the offending word
+func TestQuery_ValidateError(t *testing.T) {
+	err := NewQuery().Where("a", "1").Op("&").Validate()
+
+	var queryErr *QueryError
+	require.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, 1, queryErr.Index)
+	assert.Equal(t, "#&", queryErr.Word)
+	assert.EqualError(t, err, `query: word 1 "#&": '&' needs two values`)
+}
+
+// TestQuery_NotTwice tests that calling Not twice cancels the negation
+func TestQuery_NotTwice(t *testing.T) {
+	assert.Equal(t, []string{"comment"}, NewQuery().Not().Not().Has("comment").Words())
+}
+
+// TestClient_RunQuery tests that RunQuery posts the compiled payload
+func TestClient_RunQuery(t *testing.T) {
+	server, requests := setupRecordingServer(t, `[]`)
+
+	client, err := NewClient(serverHost(server))
+	require.NoError(t, err)
+
+	_, err = client.RunQuery(context.Background(), "ip/address/print",
+		NewQuery().Where("interface", "ether1").Proplist("address"))
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 1)
+	assert.Equal(t, MethodPost, (*requests)[0].Method)
+	assert.Equal(t, "/rest/ip/address/print", (*requests)[0].Path)
+	assert.JSONEq(t, `{".query":["interface=ether1"],".proplist":["address"]}`, (*requests)[0].Body)
+
+	// An invalid query is rejected before any request is sent
+	_, err = client.RunQuery(context.Background(), "ip/address/print", NewQuery().Op("|"))
+	assert.Error(t, err)
+	assert.Len(t, *requests, 1)
+}
@@ -3,23 +3,31 @@ package routerosv7_restfull_api
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // requestConfig represents a request to the API.
 type requestConfig struct {
-	URL      string // URL for the request to Mikrotik Router
-	Method   string // Method for the request to Mikrotik Router
-	Payload  []byte // Payload for the request to Mikrotik Router
-	Username string // Username for the request to Mikrotik Router
-	Password string // Password for the request to Mikrotik Router
+	URL       string // URL for the request to Mikrotik Router
+	Method    string // Method for the request to Mikrotik Router
+	Payload   []byte // Payload for the request to Mikrotik Router
+	Username  string // Username for the request to Mikrotik Router
+	Password  string // Password for the request to Mikrotik Router
+	UserAgent string // User-Agent header for the request to Mikrotik Router
+
+	Header http.Header // Extra headers for the request to Mikrotik Router
+
+	AllowDowngrade bool                 // Whether the request is resent over HTTP when the TLS handshake fails
+	OnDowngrade    func(DowngradeEvent) // Function called before the request is resent over HTTP
+
+	Logger *slog.Logger // Logger of the request, nil to discard the logs
 }
 
 /*
@@ -99,13 +107,13 @@ func createRequestBody(payload []byte) io.Reader {
 	return nil
 }
 
-// closeResponseBody function is used to close the response body and log the error if any error occurs
-func closeResponseBody(body io.ReadCloser) {
+// closeResponseBody function is used to close the response body and log the error with the logger if any error occurs
+func closeResponseBody(logger *slog.Logger, body io.ReadCloser) {
 	err := body.Close() // Close the response body
 
 	// Check if there is an error while closing the response body
 	if err != nil {
-		log.Println(err)
+		loggerOrDiscard(logger).Warn("routeros: closing the response body failed", slog.Any("error", err))
 	}
 }
 
@@ -118,38 +126,18 @@ func validateRequestConfig(config requestConfig) error {
 
 	// Check if the URL is valid
 	if !isValidURL(config.URL) {
-		return fmt.Errorf("makeRequest: invalid URL: %s", config.URL) // Return an error
+		return fmt.Errorf("routeros: invalid URL: %s", config.URL) // Return an error
 	}
 
 	// Check if the HTTP method is valid
 	if !isValidHTTPMethod(config.Method) {
-		return fmt.Errorf("makeRequest: invalid HTTP method: %s", config.Method) // Return an error
+		return fmt.Errorf("routeros: invalid HTTP method: %s", config.Method) // Return an error
 	}
 
 	// Check if the payload is not empty
 	return nil
 }
 
-/*
-createHTTPClient function is used to create an HTTP client with TLS configuration if the protocol is https or http
-client otherwise and returns the HTTP client pointer and nil error
-*/
-func createHTTPClient(protocol string) *http.Client {
-	// Create an HTTP client
-	client := &http.Client{}
-
-	// if the protocol is https, create an HTTP client with TLS configuration
-	if protocol == httpsProtocol {
-		// Create an HTTP client with TLS configuration
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{},
-		}
-	}
-
-	// Return the HTTP client pointer and nil error
-	return client
-}
-
 // Decode the JSON body and return the data as interface{} and nil error
 func decodeJSONBody(body io.ReadCloser) (interface{}, error) {
 
@@ -166,10 +154,12 @@ func decodeJSONBody(body io.ReadCloser) (interface{}, error) {
 }
 
 /*
-handleHTTPError function is used to handle the HTTP error and return the error message and response body
-if any error occurs while reading the response body, it logs the error and returns the HTTP error message
+handleHTTPError function is used to handle the HTTP error and return an *APIError carrying the status code,
+the RouterOS error fields and the raw response body
+if any error occurs while reading the response body, it logs the error with the logger and returns the APIError
+without body
 */
-func handleHTTPError(response *http.Response) error {
+func handleHTTPError(logger *slog.Logger, response *http.Response) error {
 
 	// Check if the response is nil
 	if response == nil {
@@ -185,15 +175,15 @@ func handleHTTPError(response *http.Response) error {
 	body, err := io.ReadAll(response.Body)
 
 	// Close the response body
-	closeResponseBody(response.Body)
+	closeResponseBody(logger, response.Body)
 
 	// Check if there is an error while reading the response body
 	if err != nil {
-		log.Println(err) // Log the error
+		loggerOrDiscard(logger).Warn("routeros: reading the error response body failed", slog.Any("error", err))
 	}
 
-	// Return the HTTP error message and response body
-	return fmt.Errorf("HTTP error: %s, Response body: %s", response.Status, string(body))
+	// Return the APIError with the response body
+	return newAPIError(response, body)
 }
 
 // setRequestAuth sets BasicAuth on the request if username and password are provided
@@ -205,6 +195,22 @@ func setRequestAuth(request *http.Request, username, password string) {
 	}
 }
 
+// setRequestUserAgent sets the User-Agent header if a user agent is provided
+func setRequestUserAgent(request *http.Request, userAgent string) {
+
+	// Check if the user agent is not empty
+	if userAgent != "" {
+		request.Header.Set("User-Agent", userAgent) // Set User-Agent header
+	}
+}
+
+// setRequestHeaders sets the extra headers on the request, replacing the headers set before
+func setRequestHeaders(request *http.Request, header http.Header) {
+	for name, values := range header {
+		request.Header[http.CanonicalHeaderKey(name)] = values
+	}
+}
+
 // setRequestContentType sets Content-Type header to application/json
 func setRequestContentType(request *http.Request) {
 	request.Header.Set("Content-Type", "application/json") // Set Content-Type header to application/json
@@ -278,14 +284,23 @@ func retryTlsErrorRequest(httpClient *http.Client, request *http.Request, config
 	return httpClient.Do(request)
 }
 
-// sendRequest sends the HTTP request and handles retry logic for TLS errors
+/*
+sendRequest sends the HTTP request. When the configuration allows it, the request is resent over HTTP
+after a TLS handshake failure, otherwise the error is returned and the credentials never leave TLS.
+*/
 func sendRequest(httpClient *http.Client, request *http.Request, config requestConfig) (*http.Response, error) {
 
 	// Send the HTTP request and return the response and error
 	response, err := doRequest(httpClient, request)
 
-	// Check if there is an error while sending the HTTP request
-	if err != nil && shouldRetryTlsErrorRequest(err, request.URL.Scheme) {
+	// Check if there is an error while sending the HTTP request and if a downgrade is allowed
+	if err != nil && config.AllowDowngrade && shouldRetryTlsErrorRequest(err, request.URL.Scheme) {
+		notifyDowngrade(config, DowngradeEvent{
+			Method:      config.Method,
+			URL:         request.URL.String(),
+			FallbackURL: replaceProtocol(request.URL.String(), httpsProtocol, httpProtocol),
+			Err:         err,
+		})
 		return retryTlsErrorRequest(httpClient, request, config) // Retry the request
 	}
 
@@ -298,19 +313,57 @@ func doRequest(httpClient *http.Client, request *http.Request) (*http.Response,
 	return httpClient.Do(request)
 }
 
-// makeRequest function
-func makeRequest(ctx context.Context, config requestConfig) (interface{}, error) {
+// decodeResponseBody decodes the JSON response body of the request, an empty body is decoded as nil
+func decodeResponseBody(config requestConfig, body []byte) (interface{}, error) {
 
-	// Validate the request config struct fields before making the request to the API
-	if err := validateRequestConfig(config); err != nil {
-		return nil, err // Return nil and error
+	// Check if the response body is empty, e.g. for a DELETE request
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil // Return nil result and nil error
 	}
 
-	// Determine the protocol from the URL
-	protocol := determineProtocolFromURL(config.URL)
+	// Decode the JSON body
+	result, err := decodeJSONBody(io.NopCloser(bytes.NewReader(body)))
 
-	// Create an HTTP client
-	httpClient := createHTTPClient(protocol)
+	// Check if there is an error while decoding the JSON body
+	if err != nil {
+		return nil, &DecodeError{Method: config.Method, URL: config.URL, Err: err} // Return nil and error
+	}
+
+	// Return the data as interface{} and nil error
+	return result, nil
+}
+
+// logResponse logs the response of the request at the Debug level
+func logResponse(ctx context.Context, logger *slog.Logger, request *http.Request, status int, start time.Time,
+	size int) {
+	logger.DebugContext(ctx, "routeros response",
+		slog.String("method", request.Method),
+		slog.String("path", request.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.Int("bytes", size),
+	)
+}
+
+// responseBodySize returns the size of the response body carried by an APIError
+func responseBodySize(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return len(apiErr.Body)
+	}
+	return 0
+}
+
+/*
+fetchResponse function sends the request with the provided HTTP client and returns the status code and the raw
+response body. It returns an *APIError if the response status code is not in the range 200-299
+*/
+func fetchResponse(ctx context.Context, httpClient *http.Client, config requestConfig) (int, []byte, error) {
+
+	// Validate the request config struct fields before making the request to the API
+	if err := validateRequestConfig(config); err != nil {
+		return 0, nil, err // Return nil and error
+	}
 
 	// Create the request body from the payload
 	requestBody := createRequestBody(config.Payload)
@@ -320,28 +373,55 @@ func makeRequest(ctx context.Context, config requestConfig) (interface{}, error)
 
 	// Check if there is an error while creating the HTTP request
 	if err != nil {
-		return nil, fmt.Errorf("makeRequest: request creation failed: %w", err) // Return nil and error
+		return 0, nil, fmt.Errorf("routeros: request creation failed: %w", err) // Return nil and error
 	}
 
+	// Set the User-Agent header and the extra headers
+	setRequestUserAgent(request, config.UserAgent)
+	setRequestHeaders(request, config.Header)
+
+	// Log the request with its secrets redacted
+	logger := loggerOrDiscard(config.Logger)
+	logger.DebugContext(ctx, "routeros request",
+		slog.String("method", request.Method),
+		slog.String("path", request.URL.Path),
+		slog.Any("header", redactedHeader(request.Header)),
+		slog.Any("payload", redactedPayload(config.Payload)),
+		slog.Int("bytes", len(config.Payload)),
+	)
+
 	// Send the HTTP request and return the response and error
+	start := time.Now()
 	response, err := sendRequest(httpClient, request, config)
 
 	// Check if there is an error while sending the HTTP request
 	if err != nil {
-		return nil, err // Return nil and error
+		logger.DebugContext(ctx, "routeros request failed", slog.String("method", request.Method),
+			slog.String("path", request.URL.Path), slog.Duration("latency", time.Since(start)), slog.Any("error", err))
+		return 0, nil, wrapSendError(config, err) // Return nil and error
 	}
 
 	// Close the response body
-	defer closeResponseBody(response.Body)
+	defer closeResponseBody(logger, response.Body)
 
 	// Check if the response status code is not in the range 200-299
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return nil, handleHTTPError(response) // Return nil and error
+		err := handleHTTPError(logger, response)
+		logResponse(ctx, logger, request, response.StatusCode, start, responseBodySize(err))
+		return response.StatusCode, nil, err // Return the status code and error
+	}
+
+	// Read the response body
+	body, err := io.ReadAll(response.Body)
+
+	// Check if there is an error while reading the response body
+	if err != nil {
+		return response.StatusCode, nil, wrapSendError(config, err) // Return the status code and error
 	}
 
-	// Decode the JSON body and return the data as interface{} and nil error
-	result, err := decodeJSONBody(response.Body)
+	// Log the response
+	logResponse(ctx, logger, request, response.StatusCode, start, len(body))
 
-	// Return the data as interface{} and nil error
-	return result, err
+	// Return the status code and the raw response body
+	return response.StatusCode, body, nil
 }
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -203,8 +204,20 @@ func TestCloseResponseBody(t *testing.T) {
 	// Mock a response body with an error on close
 	errorBody := &mockErrorReaderCloser{} // Mock a response body with an error on close
 
+	// Capture the log output
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
 	// Close the response body
-	closeResponseBody(errorBody) // This should log the error, you can capture logs and check
+	closeResponseBody(logger, errorBody) // This should log the error with the logger
+
+	// Check if the error is logged
+	if !strings.Contains(output.String(), "mocked close error") {
+		t.Errorf("Expected the close error to be logged, got %q", output.String())
+	}
+
+	// Check if nothing is logged without a logger
+	closeResponseBody(nil, errorBody)
 }
 
 // TestValidateRequestConfig tests the validateRequestConfig function with various inputs and expected outputs for each test case defined in the tests array of struct below it.
@@ -240,24 +253,15 @@ func TestValidateRequestConfig(t *testing.T) {
 	}
 }
 
-// TestCreateHTTPClient tests the createHTTPClient function with various inputs and expected outputs for each test case
-// defined in the tests array of struct below it.
-func TestCreateHTTPClient(t *testing.T) {
-	// Test for HTTPS protocol
-	client := createHTTPClient(httpsProtocol)
-
-	// Check if the TLS config is set for HTTPS protocol and not set for HTTP protocol for the client created above
-	//and below respectively in the test cases.
-	if client.Transport == nil || client.Transport.(*http.Transport).TLSClientConfig == nil {
-		t.Error("Expected TLS config to be set for HTTPS protocol")
-	}
-
-	// Test for HTTP protocol (no TLS config)
-	client = createHTTPClient(httpProtocol)
+// TestNewClient_PackageTransport tests that the clients of the package-level functions share the package transport
+func TestNewClient_PackageTransport(t *testing.T) {
+	client, err := newClient("https://192.168.88.1", "admin", "password")
+	assertNoError(t, err)
 
-	// Check if the TLS config is not set for HTTP protocol for the client created above and below respectively in the test cases.
-	if client.Transport != nil {
-		t.Error("Expected no TLS config for HTTP protocol") // Check if the TLS config is not set for HTTP protocol for the client created above and below respectively in the test cases.
+	// Check if the shared transport with its TLS configuration is used
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok || transport != packageTransport() || transport.TLSClientConfig == nil {
+		t.Error("Expected the shared package transport with a TLS config")
 	}
 }
 
@@ -269,7 +273,7 @@ func TestHandleHTTPError_Non2xxWithBody(t *testing.T) {
 	response.WriteHeader(statusCode)        // Set the status code to 404
 	response.Body.WriteString(responseBody) // Write the response body to the response
 
-	err := handleHTTPError(response.Result()) // Handle the HTTP error
+	err := handleHTTPError(discardLogger, response.Result()) // Handle the HTTP error
 
 	// Check if the error is as expected
 	expectedError := fmt.Sprintf("HTTP error: %d %s, Response body: %s", statusCode, http.StatusText(statusCode), responseBody)
@@ -284,7 +288,7 @@ func TestHandleHTTPError_Non2xxWithoutBody(t *testing.T) {
 	response := httptest.NewRecorder()           // Create a new recorder
 	response.WriteHeader(statusCode)             // Set the status code to 500
 
-	err := handleHTTPError(response.Result()) // Handle the HTTP error
+	err := handleHTTPError(discardLogger, response.Result()) // Handle the HTTP error
 
 	// Check if the error is as expected
 	expectedError := fmt.Sprintf("HTTP error: %d %s, Response body: ", statusCode, http.StatusText(statusCode))
@@ -299,7 +303,7 @@ func TestHandleHTTPError_Non2xxEmptyBody(t *testing.T) {
 	response := httptest.NewRecorder()  // Create a new recorder
 	response.WriteHeader(statusCode)    // Set the status code to 400
 
-	err := handleHTTPError(response.Result()) // Handle the HTTP error
+	err := handleHTTPError(discardLogger, response.Result()) // Handle the HTTP error
 
 	// Check if the error is as expected
 	expectedError := fmt.Sprintf("HTTP error: %d %s, Response body: ", statusCode, http.StatusText(statusCode))
@@ -316,7 +320,7 @@ func TestHandleHTTPError_Non2xxLargeBody(t *testing.T) {
 	largeBody := "This is a very large response body. " + string(make([]byte, 1024*1024)) // 1 MB
 	response.Body.WriteString(largeBody)                                                  // Write the large body to the response
 
-	err := handleHTTPError(response.Result()) // Handle the HTTP error
+	err := handleHTTPError(discardLogger, response.Result()) // Handle the HTTP error
 
 	// Check if the error is as expected
 	expectedError := fmt.Sprintf("HTTP error: %d %s, Response body: %s", statusCode, http.StatusText(statusCode), largeBody)
@@ -337,7 +341,7 @@ func TestHandleHTTPError_NilResponseBody(t *testing.T) {
 	}
 
 	// Handle the HTTP error
-	err := handleHTTPError(response)
+	err := handleHTTPError(discardLogger, response)
 
 	// Check if the error is as expected
 	expectedError := "nil HTTP response body"
@@ -356,7 +360,7 @@ func TestHandleHTTPError_ResponseBodyReadError(t *testing.T) {
 	}
 
 	// Handle the HTTP error
-	err := handleHTTPError(response)
+	err := handleHTTPError(discardLogger, response)
 
 	// Check if the error is as expected
 	expectedError := "HTTP error: 404 Not Found, Response body: "
@@ -376,7 +380,7 @@ func TestHandleHTTPError_ResponseBodyCloseError(t *testing.T) {
 	}
 
 	// Handle the HTTP error
-	err := handleHTTPError(response)
+	err := handleHTTPError(discardLogger, response)
 
 	// Check if the error is as expected
 	expectedError := "HTTP error: 404 Not Found, Response body: "
@@ -858,9 +862,9 @@ func TestRetryTlsErrorRequest(t *testing.T) {
 
 // Helper function for reading the response body
 func readResponseBody(response *http.Response) string {
-	body, _ := io.ReadAll(response.Body)   // read the response body
-	defer closeResponseBody(response.Body) // close the response body
-	return string(body)                    // return the response body as a string
+	body, _ := io.ReadAll(response.Body)                  // read the response body
+	defer closeResponseBody(discardLogger, response.Body) // close the response body
+	return string(body)                                   // return the response body as a string
 }
 
 // TestSendRequest_Success tests the sendRequest function with a successful HTTP request
@@ -1176,8 +1180,25 @@ func assertNotNil(t *testing.T, v interface{}) {
 	}
 }
 
-// TestMakeRequest_Successful tests the makeRequest function with a successful HTTP request
-func TestMakeRequest_Successful(t *testing.T) {
+// newSampleClient creates a client of the mock server with the credentials of the sample configuration
+func newSampleClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	client, err := NewClient(server.URL, WithCredentials("test", "password"))
+	if err != nil {
+		t.Fatalf("NewClient() returned an unexpected error: %v", err)
+	}
+	return client
+}
+
+// fetchSampleConfig sends the request configuration with a plain HTTP client
+func fetchSampleConfig(config requestConfig) error {
+	_, _, err := fetchResponse(context.Background(), &http.Client{}, config)
+	return err
+}
+
+// TestClient_Successful tests a successful HTTP request of a client
+func TestClient_Successful(t *testing.T) {
 
 	// Create a mock server with a 200 status code (OK) and a success response body
 	server := setupMockServer(http.StatusOK, `{"status": "success"}`)
@@ -1185,31 +1206,25 @@ func TestMakeRequest_Successful(t *testing.T) {
 	// Close the mock server
 	defer server.Close()
 
-	// Create a request configuration with the mock server URL
-	config := createSampleConfig(server.URL)
-
 	// Attempt to make a request with a 200 status code (OK) and a success response body
-	response, err := makeRequest(context.Background(), config)
+	response, err := newSampleClient(t, server).Print(context.Background(), "system/resource")
 
 	assertNoError(t, err)     // Check if there is no error for a successful HTTP request
 	assertNotNil(t, response) // Check if the response is not nil for a successful HTTP request
 }
 
-// TestMakeRequest_InvalidURL tests the makeRequest function is tested for an error for an invalid URL
-func TestMakeRequest_InvalidURL(t *testing.T) {
-
-	// Create a request configuration with an invalid URL
-	config := createSampleConfig("invalid-url")
+// TestFetchResponse_InvalidURL tests the fetchResponse function is tested for an error for an invalid URL
+func TestFetchResponse_InvalidURL(t *testing.T) {
 
 	// Attempt to make a request with an invalid URL
-	_, err := makeRequest(context.Background(), config)
+	err := fetchSampleConfig(createSampleConfig("invalid-url"))
 
 	// Check if there is an error for an invalid URL
-	assertErrorContains(t, err, "makeRequest: invalid URL")
+	assertErrorContains(t, err, "routeros: invalid URL")
 }
 
-// TestMakeRequest_Non2xxStatusCode tests the makeRequest function is tested for an error for a non-2xx status code
-func TestMakeRequest_Non2xxStatusCode(t *testing.T) {
+// TestClient_Non2xxStatusCode tests that a client returns an error for a non-2xx status code
+func TestClient_Non2xxStatusCode(t *testing.T) {
 
 	// Create a mock server with a 404 status code (Not Found) and an error response body
 	server := setupMockServer(http.StatusNotFound, `{"error": "not found"}`)
@@ -1217,18 +1232,16 @@ func TestMakeRequest_Non2xxStatusCode(t *testing.T) {
 	// Close the mock server
 	defer server.Close()
 
-	// Create a request configuration with the mock server URL
-	config := createSampleConfig(server.URL)
-
 	// Attempt to make a request with a 404 status code (Not Found) and an error response body
-	_, err := makeRequest(context.Background(), config)
+	_, err := newSampleClient(t, server).Print(context.Background(), "ip/address")
 
 	// Check if there is an error for a non-2xx status code and an error response body
 	assertError(t, err, "Expected an error for non-2xx status code")
 }
 
-// TestMakeRequest_Non2xxStatusCode_EmptyResponseBody tests the makeRequest function is tested for an error for a non-2xx
-func TestMakeRequest_Non2xxStatusCode_EmptyResponseBody(t *testing.T) {
+// TestClient_Non2xxStatusCode_EmptyResponseBody tests that a client returns an error for a non-2xx status code
+// without response body
+func TestClient_Non2xxStatusCode_EmptyResponseBody(t *testing.T) {
 
 	// Create a mock server with a 404 status code (Not Found) and an empty response body
 	server := setupMockServer(http.StatusNotFound, "")
@@ -1236,18 +1249,16 @@ func TestMakeRequest_Non2xxStatusCode_EmptyResponseBody(t *testing.T) {
 	// Close the mock server
 	defer server.Close()
 
-	// Create a request configuration with the mock server URL
-	config := createSampleConfig(server.URL)
-
 	// Attempt to make a request with a 404 status code (Not Found) and an empty response body
-	_, err := makeRequest(context.Background(), config)
+	_, err := newSampleClient(t, server).Print(context.Background(), "ip/address")
 
 	// Check if there is an error for a non-2xx status code and an empty response body
 	assertError(t, err, "Expected an error for non-2xx status code")
 }
 
-// TestMakeRequest_Non2xxStatusCode_LargeResponseBody tests the makeRequest function is tested for an error for a non-2xx
-func TestMakeRequest_Non2xxStatusCode_LargeResponseBody(t *testing.T) {
+// TestClient_Non2xxStatusCode_LargeResponseBody tests that a client returns an error for a non-2xx status code
+// with a large response body
+func TestClient_Non2xxStatusCode_LargeResponseBody(t *testing.T) {
 
 	// Create a mock server with a 404 status code (Not Found) and a large response body
 	server := setupMockServer(http.StatusNotFound, string(make([]byte, 1024*1024))) // 1 MB
@@ -1255,18 +1266,15 @@ func TestMakeRequest_Non2xxStatusCode_LargeResponseBody(t *testing.T) {
 	// Close the mock server
 	defer server.Close()
 
-	// Create a request configuration with the mock server URL
-	config := createSampleConfig(server.URL)
-
 	// Attempt to make a request with a 404 status code (Not Found) and a large response body
-	_, err := makeRequest(context.Background(), config)
+	_, err := newSampleClient(t, server).Print(context.Background(), "ip/address")
 
 	// Check if there is an error for a non-2xx status code and a large response body
 	assertError(t, err, "Expected an error for non-2xx status code")
 }
 
-// TestMakeRequest_InvalidMethod tests the makeRequest function is tested for an error Invalid HTTP method
-func TestMakeRequest_InvalidMethod(t *testing.T) {
+// TestFetchResponse_InvalidMethod tests the fetchResponse function is tested for an error Invalid HTTP method
+func TestFetchResponse_InvalidMethod(t *testing.T) {
 
 	// Create a request configuration with an valid URL
 	config := createSampleConfig("http://example.com")
@@ -1275,14 +1283,15 @@ func TestMakeRequest_InvalidMethod(t *testing.T) {
 	config.Method = "INVALID"
 
 	// Attempt to make a request with an invalid HTTP method
-	_, err := makeRequest(context.Background(), config)
+	err := fetchSampleConfig(config)
 
 	// Check if there is an error for an invalid HTTP method
-	assertError(t, err, "Expected an error for invalid HTTP method")
+	assertErrorContains(t, err, "routeros: invalid HTTP method")
 }
 
-// TestMakeRequest_InvalidURLAndMethod tests the makeRequest function is tested for an error parsing URL and HTTP method
-func TestMakeRequest_InvalidURLAndMethod(t *testing.T) {
+// TestFetchResponse_InvalidURLAndMethod tests the fetchResponse function is tested for an error parsing URL and
+// HTTP method
+func TestFetchResponse_InvalidURLAndMethod(t *testing.T) {
 
 	// Create a request configuration with an invalid URL
 	config := createSampleConfig("invalid-url")
@@ -1291,28 +1300,25 @@ func TestMakeRequest_InvalidURLAndMethod(t *testing.T) {
 	config.Method = "INVALID"
 
 	// Attempt to make a request with an invalid URL and HTTP method
-	_, err := makeRequest(context.Background(), config)
+	err := fetchSampleConfig(config)
 
 	// Check if there is an error for an invalid URL and HTTP method
 	assertError(t, err, "Expected an error for invalid URL and HTTP method")
 }
 
-// TestMakeRequest_ErrorParsingURL tests the makeRequest function is tested for an error parsing URL
-func TestMakeRequest_ErrorParsingURL(t *testing.T) {
-
-	// Create a request configuration with an invalid URL
-	config := createSampleConfig(":invalid-url")
+// TestFetchResponse_ErrorParsingURL tests the fetchResponse function is tested for an error parsing URL
+func TestFetchResponse_ErrorParsingURL(t *testing.T) {
 
 	// Attempt to make a request with an invalid URL
-	_, err := makeRequest(context.Background(), config)
+	err := fetchSampleConfig(createSampleConfig(":invalid-url"))
 
 	// Check if there is an error for an error parsing URL
 	assertError(t, err, "Expected an error for error parsing URL")
 }
 
-// TestMakeRequest_ErrorParsingURLAndMethod tests the makeRequest function is tested for an error parsing URL and HTTP method
-// for the test case defined in the test array of struct below it.
-func TestMakeRequest_ErrorParsingURLAndMethod(t *testing.T) {
+// TestFetchResponse_ErrorParsingURLAndMethod tests the fetchResponse function is tested for an error parsing URL and
+// HTTP method
+func TestFetchResponse_ErrorParsingURLAndMethod(t *testing.T) {
 
 	// Create a request configuration with an invalid URL and HTTP method
 	config := createSampleConfig(":invalid-url")
@@ -1321,7 +1327,7 @@ func TestMakeRequest_ErrorParsingURLAndMethod(t *testing.T) {
 	config.Method = "INVALID"
 
 	// Attempt to make a request with an invalid URL and HTTP method
-	_, err := makeRequest(context.Background(), config)
+	err := fetchSampleConfig(config)
 
 	// Check if there is an error for an invalid URL and HTTP method
 	assertError(t, err, "Expected an error for invalid URL and HTTP method")
@@ -1337,7 +1343,7 @@ func TestHandleHTTPError_BadRequest(t *testing.T) {
 	}
 
 	// Attempt to handle the error
-	err := handleHTTPError(errorResponse)
+	err := handleHTTPError(discardLogger, errorResponse)
 
 	// Check if there is no error for a 400 status code (Bad Request)
 	assertErrorContains(t, err, "HTTP error")
@@ -1347,7 +1353,7 @@ func TestHandleHTTPError_BadRequest(t *testing.T) {
 func TestHandleHTTPError_NilResponse(t *testing.T) {
 
 	// Attempt to handle the error
-	err := handleHTTPError(nil)
+	err := handleHTTPError(discardLogger, nil)
 
 	// Check if there is an error for a nil response
 	assertError(t, err, "Expected an error for nil response")
@@ -1364,7 +1370,7 @@ func TestHandleHTTPError_EmptyResponseBody(t *testing.T) {
 	}
 
 	// Attempt to handle the error
-	err := handleHTTPError(errorResponse)
+	err := handleHTTPError(discardLogger, errorResponse)
 
 	// Check if there is an error for an empty response body
 	assertError(t, err, "Expected an error for empty response body")
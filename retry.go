@@ -0,0 +1,194 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+RetryPolicy configures how a Client retries the requests failing with a transient error, such as a reset
+connection or a 503 answered while the router CPU is busy. Only idempotent requests are retried: GET, DELETE,
+print commands and the requests whose context was marked with WithIdempotent.
+*/
+type RetryPolicy struct {
+	MaxAttempts    int                                    // Number of attempts including the first one, 1 disables the retries
+	InitialBackoff time.Duration                          // Wait before the first retry
+	MaxBackoff     time.Duration                          // Upper bound of the wait between two attempts
+	Multiplier     float64                                // Factor applied to the wait after every retry, at least 1
+	Jitter         float64                                // Fraction of the wait randomly removed, between 0 and 1
+	Retryable      func(request *Request, err error) bool // Classifier of the retryable errors, DefaultRetryable if nil
+}
+
+// DefaultRetryPolicy returns a policy making 3 attempts with an exponential backoff starting at 200 milliseconds
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// validate checks the fields of the policy
+func (p RetryPolicy) validate() error {
+	switch {
+	case p.MaxAttempts < 1:
+		return errors.New("RetryPolicy: MaxAttempts must be positive")
+	case p.InitialBackoff < 0 || p.MaxBackoff < 0:
+		return errors.New("RetryPolicy: backoff must not be negative")
+	case p.Multiplier < 1:
+		return errors.New("RetryPolicy: Multiplier must be at least 1")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return errors.New("RetryPolicy: Jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+/*
+backoff returns the wait before the given retry, starting at 1: the initial backoff multiplied for every
+previous retry, capped by the maximum backoff, minus a random fraction of at most Jitter
+*/
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		wait *= p.Multiplier
+	}
+
+	// Cap the wait
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+
+	// Remove the jitter
+	wait -= wait * p.Jitter * rand.Float64()
+	return time.Duration(wait)
+}
+
+/*
+WithRetryPolicy retries the idempotent requests failing with a retryable error according to the policy.
+The retries happen inside the middlewares added with WithMiddleware, which see a single request and its final result.
+example:
+NewClient("192.168.88.1", WithRetryPolicy(DefaultRetryPolicy()))
+*/
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) error {
+		// Check if the policy is valid
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("WithRetryPolicy: %w", err)
+		}
+		o.retryPolicy = &policy // Set the retry policy
+		return nil
+	}
+}
+
+// idempotentKey is the context key marking a request as idempotent
+type idempotentKey struct{}
+
+/*
+WithIdempotent returns a context marking the requests made with it as idempotent, so that a PUT or POST request
+is retried by the retry policy. Only mark requests that are safe to repeat, e.g. a set of the same values.
+*/
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+/*
+IsIdempotent reports whether the request may be repeated safely: GET and DELETE requests,
+POST requests of a print command, and the requests whose context was marked with WithIdempotent
+*/
+func IsIdempotent(ctx context.Context, request *Request) bool {
+
+	// Check if the caller marked the request as idempotent
+	if marked, _ := ctx.Value(idempotentKey{}).(bool); marked {
+		return true
+	}
+
+	switch request.Method {
+	case MethodGet, MethodDelete:
+		return true
+	case MethodPost:
+		return isPrintCommand(request.Command)
+	}
+	return false
+}
+
+// isPrintCommand checks if the last segment of the command path is print, e.g. "ip/address/print"
+func isPrintCommand(command string) bool {
+	command, _, _ = strings.Cut(command, "?")
+	command = strings.TrimSuffix(command, "/")
+	return command == "print" || strings.HasSuffix(command, "/print")
+}
+
+/*
+DefaultRetryable reports whether the error is transient: a connection error other than a cancelled context,
+or a 429, 502, 503 or 504 status code. TLS errors and other API errors are not retried.
+*/
+func DefaultRetryable(_ *Request, err error) bool {
+
+	// Check if the context ended
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Check if the router answered with a transient status code
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	// Check if the connection failed
+	var transportErr *TransportError
+	return errors.As(err, &transportErr)
+}
+
+// retryMiddleware retries the idempotent requests failing with a retryable error according to the policy
+func retryMiddleware(policy RetryPolicy) Middleware {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			response, err := next.Do(ctx, request)
+
+			// Retry while the attempt failed with a retryable error
+			for attempt := 2; attempt <= policy.MaxAttempts; attempt++ {
+				if err == nil || !IsIdempotent(ctx, request) || !retryable(request, err) {
+					break
+				}
+
+				// Wait for the backoff or the end of the context
+				timer := time.NewTimer(policy.backoff(attempt - 1))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return response, err // Return the error of the last attempt
+				case <-timer.C:
+				}
+
+				response, err = next.Do(ctx, request)
+
+				/*
+					A DELETE retried after a lost response may find the item already removed by the previous
+					attempt, the removal succeeded
+				*/
+				if request.Method == MethodDelete && IsNotFound(err) {
+					return &Response{StatusCode: http.StatusNoContent}, nil
+				}
+			}
+			return response, err
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetryPolicy is a retry policy with short backoffs for the tests
+func fastRetryPolicy(maxAttempts int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond,
+		Multiplier: 2}
+}
+
+/*
+setupFlakyServer creates a server answering the given responses in order, the last one being repeated.
+A zero status closes the connection without answering.
+*/
+func setupFlakyServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int64) {
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := int(attempts.Add(1))
+		status := statuses[len(statuses)-1]
+		if attempt <= len(statuses) {
+			status = statuses[attempt-1]
+		}
+
+		// Drop the connection
+		if status == 0 {
+			connection, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = connection.Close()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status < 300 {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"error":` + strconv.Itoa(status) + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+// newRetryClient creates a client for the server with the retry policy
+func newRetryClient(t *testing.T, server *httptest.Server, policy RetryPolicy) *Client {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	client, err := NewClient(serverHost(server), WithRetryPolicy(policy))
+	require.NoError(t, err)
+	return client
+}
+
+// TestRetryPolicy_RetriesTransientErrors tests that GET and print requests are retried until they succeed
+func TestRetryPolicy_RetriesTransientErrors(t *testing.T) {
+	server, attempts := setupFlakyServer(t, http.StatusServiceUnavailable, 0, http.StatusOK)
+	client := newRetryClient(t, server, fastRetryPolicy(3))
+
+	_, err := client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), attempts.Load())
+
+	// A print command sent with POST is retried too
+	server, attempts = setupFlakyServer(t, http.StatusBadGateway, http.StatusOK)
+	client = newRetryClient(t, server, fastRetryPolicy(3))
+
+	_, err = client.Run(context.Background(), "ip/address/print", []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), attempts.Load())
+}
+
+// TestRetryPolicy_MaxAttempts tests that the error of the last attempt is returned when every attempt fails
+func TestRetryPolicy_MaxAttempts(t *testing.T) {
+	server, attempts := setupFlakyServer(t, http.StatusServiceUnavailable)
+	client := newRetryClient(t, server, fastRetryPolicy(4))
+
+	_, err := client.Print(context.Background(), "ip/address")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int64(4), attempts.Load())
+}
+
+// TestRetryPolicy_NonIdempotent tests that PUT and POST requests are only retried when marked idempotent
+func TestRetryPolicy_NonIdempotent(t *testing.T) {
+	server, attempts := setupFlakyServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	client := newRetryClient(t, server, fastRetryPolicy(3))
+
+	_, err := client.Add(context.Background(), "ip/address", []byte(`{"address":"10.0.0.1/24"}`))
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), attempts.Load())
+
+	_, err = client.Run(context.Background(), "system/reboot", nil)
+	require.NoError(t, err) // The second answer of the server
+	assert.Equal(t, int64(2), attempts.Load())
+
+	// The caller marks the request as idempotent
+	server, attempts = setupFlakyServer(t, 0, http.StatusOK)
+	client = newRetryClient(t, server, fastRetryPolicy(3))
+
+	_, err = client.Set(WithIdempotent(context.Background()), "ip/address/*1", []byte(`{"comment":"lan"}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), attempts.Load())
+}
+
+// TestRetryPolicy_NonRetryableError tests that errors other than transient ones are not retried
+func TestRetryPolicy_NonRetryableError(t *testing.T) {
+	server, attempts := setupFlakyServer(t, http.StatusBadRequest, http.StatusOK)
+	client := newRetryClient(t, server, fastRetryPolicy(3))
+
+	_, err := client.Print(context.Background(), "ip/address")
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), attempts.Load())
+}
+
+// TestRetryPolicy_DeleteOfMissingItem tests that a retried DELETE finding the item removed succeeds
+func TestRetryPolicy_DeleteOfMissingItem(t *testing.T) {
+	server, attempts := setupFlakyServer(t, 0, http.StatusNotFound)
+	client := newRetryClient(t, server, fastRetryPolicy(3))
+
+	result, err := client.Remove(context.Background(), "ip/address/*1")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, int64(2), attempts.Load())
+
+	// The first attempt of a DELETE still reports a missing item
+	server, _ = setupFlakyServer(t, http.StatusNotFound)
+	client = newRetryClient(t, server, fastRetryPolicy(3))
+
+	_, err = client.Remove(context.Background(), "ip/address/*1")
+	assert.True(t, IsNotFound(err))
+}
+
+// TestRetryPolicy_ContextCancelled tests that the backoff stops when the context ends
+func TestRetryPolicy_ContextCancelled(t *testing.T) {
+	server, attempts := setupFlakyServer(t, http.StatusServiceUnavailable)
+	client := newRetryClient(t, server, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, Multiplier: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Print(ctx, "ip/address")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Equal(t, int64(1), attempts.Load())
+}
+
+// TestRetryPolicy_CustomClassifier tests that the classifier of the policy decides which errors are retried
+func TestRetryPolicy_CustomClassifier(t *testing.T) {
+	server, attempts := setupFlakyServer(t, http.StatusInternalServerError, http.StatusOK)
+	policy := fastRetryPolicy(3)
+	policy.Retryable = func(_ *Request, err error) bool {
+		var apiErr *APIError
+		return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusInternalServerError
+	}
+	client := newRetryClient(t, server, policy)
+
+	_, err := client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), attempts.Load())
+}
+
+// TestRetryPolicy_Backoff tests the exponential growth and the cap of the backoff
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second,
+		Multiplier: 3}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 900*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, time.Second, policy.backoff(4))
+
+	// The jitter removes at most the given fraction of the wait
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait := policy.backoff(1)
+		assert.GreaterOrEqual(t, wait, 50*time.Millisecond)
+		assert.LessOrEqual(t, wait, 100*time.Millisecond)
+	}
+}
+
+// TestWithRetryPolicy_Invalid tests that invalid policies are rejected
+func TestWithRetryPolicy_Invalid(t *testing.T) {
+	for _, policy := range []RetryPolicy{
+		{MaxAttempts: 0, Multiplier: 1},
+		{MaxAttempts: 3, InitialBackoff: -1, Multiplier: 1},
+		{MaxAttempts: 3, Multiplier: 0.5},
+		{MaxAttempts: 3, Multiplier: 1, Jitter: 2},
+	} {
+		_, err := NewClient("router.lan", WithRetryPolicy(policy))
+		assert.Error(t, err)
+	}
+
+	_, err := NewClient("router.lan", WithRetryPolicy(DefaultRetryPolicy()))
+	assert.NoError(t, err)
+}
+
+// TestIsIdempotent tests the requests considered safe to repeat
+func TestIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		method, command string
+		expected        bool
+	}{
+		{MethodGet, "ip/address", true},
+		{MethodDelete, "ip/address/*1", true},
+		{MethodPost, "ip/address/print", true},
+		{MethodPost, "print", true},
+		{MethodPost, "interface/print?.proplist=name", true},
+		{MethodPost, "system/reboot", false},
+		{MethodPost, "ip/address/printer", false},
+		{MethodPut, "ip/address", false},
+		{MethodPatch, "ip/address/*1", false},
+	}
+
+	for _, test := range tests {
+		request := &Request{Method: test.method, Command: test.command}
+		assert.Equal(t, test.expected, IsIdempotent(ctx, request), "%s %s", test.method, test.command)
+	}
+
+	assert.True(t, IsIdempotent(WithIdempotent(ctx), &Request{Method: MethodPut, Command: "ip/address"}))
+}
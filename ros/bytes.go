@@ -0,0 +1,103 @@
+package ros
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byteUnits are the RouterOS size suffixes, RouterOS always uses binary multiples
+var byteUnits = []struct {
+	suffix     string  // Suffix of the unit
+	multiplier float64 // Number of bytes in the unit
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"PiB", 1 << 50},
+	{"k", 1 << 10},
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"T", 1 << 40},
+	{"B", 1},
+}
+
+/*
+Bytes is a RouterOS size such as a memory or disk size.
+It decodes from "12.3KiB", "64k", "1.5 GiB" or a bare number of bytes and encodes as a bare number of bytes,
+fractional sizes are rounded to the nearest byte.
+*/
+type Bytes uint64
+
+// ParseBytes parses a RouterOS size, an empty string is zero
+func ParseBytes(s string) (Bytes, error) {
+	value := strings.TrimSpace(s)
+
+	// Check if the size is empty
+	if value == "" {
+		return 0, nil
+	}
+
+	// Remove the unit suffix if any
+	multiplier := 1.0
+	for _, u := range byteUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			multiplier = u.multiplier
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			break
+		}
+	}
+
+	// Parse integers exactly to keep the full uint64 range
+	if multiplier == 1 {
+		if integer, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return Bytes(integer), nil
+		}
+	}
+
+	// Parse the number
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, fmt.Errorf("ros: invalid size %q", s)
+	}
+
+	// Check if the size overflows, math.MaxUint64 is rounded up to 2^64 as a float
+	size := math.Round(number * multiplier)
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("ros: invalid size %q", s)
+	}
+	return Bytes(size), nil
+}
+
+// String returns the size as a bare number of bytes
+func (b Bytes) String() string {
+	return strconv.FormatUint(uint64(b), 10)
+}
+
+// MarshalText encodes the size as a bare number of bytes
+func (b Bytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText decodes a RouterOS size
+func (b *Bytes) UnmarshalText(text []byte) error {
+	value, err := ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+	*b = value
+	return nil
+}
+
+// MarshalJSON encodes the size as a JSON string holding a bare number of bytes
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return marshalString(b.String())
+}
+
+// UnmarshalJSON decodes a RouterOS size from a JSON string or a JSON number
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, b.UnmarshalText)
+}
@@ -0,0 +1,59 @@
+package ros
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseBytes tests the ParseBytes function with the RouterOS size formats
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input    string // Input value
+		expected Bytes  // Expected result
+		wantErr  bool   // Expected error
+	}{
+		{"1024", 1024, false},
+		{"12.3KiB", 12595, false},
+		{"1.5 MiB", 1572864, false},
+		{"2GiB", 2 << 30, false},
+		{"1TiB", 1 << 40, false},
+		{"64k", 65536, false},
+		{"10M", 10 << 20, false},
+		{"512B", 512, false},
+		{"18446744073709551615", 18446744073709551615, false},
+		{"", 0, false},
+		{"-1", 0, true},
+		{"KiB", 0, true},
+		{"lots", 0, true},
+		{"20000000PiB", 0, true},
+		{"16384PiB", 0, true},
+		{"18446744073709551616", 0, true},
+		{"1e30", 0, true},
+		{"8192PiB", 8 << 60, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseBytes(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestBytes_JSON tests that sizes round-trip through JSON
+func TestBytes_JSON(t *testing.T) {
+	var values []Bytes
+	require.NoError(t, json.Unmarshal([]byte(`["1KiB", 2048, "3"]`), &values))
+	assert.Equal(t, []Bytes{1024, 2048, 3}, values)
+
+	data, err := json.Marshal(values)
+	require.NoError(t, err)
+	assert.Equal(t, `["1024","2048","3"]`, string(data))
+
+	var size Bytes
+	assert.Error(t, json.Unmarshal([]byte(`true`), &size))
+}
@@ -0,0 +1,219 @@
+package ros
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Week is the duration of a RouterOS week, RouterOS durations use "w" as their largest unit
+const Week = 7 * 24 * time.Hour
+
+// durationUnits are the RouterOS duration units, two-letter units first so that "ms" is not read as "m"
+var durationUnits = []struct {
+	suffix string        // Suffix of the unit
+	unit   time.Duration // Length of the unit
+}{
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+	{"w", Week},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+/*
+Duration is a RouterOS duration such as an uptime or a timeout.
+It decodes from "1w2d03:04:05", "1w2d3h4m5s", "00:00:10", "500ms" or a bare number of seconds,
+and encodes as "1w2d3h4m5s".
+*/
+type Duration time.Duration
+
+// ParseDuration parses a RouterOS duration with an optional leading "-", an empty string is zero
+func ParseDuration(s string) (Duration, error) {
+	rest := strings.TrimSpace(s)
+
+	// Check if the duration is empty
+	if rest == "" {
+		return 0, nil
+	}
+
+	// Check if the duration is negative
+	negative := strings.HasPrefix(rest, "-")
+	if negative {
+		rest = rest[1:]
+		if rest == "" {
+			return 0, fmt.Errorf("ros: invalid duration %q", s)
+		}
+	}
+
+	var total time.Duration
+	var ok bool
+	for rest != "" {
+
+		// Read the number
+		i := 0
+		for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+			i++
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("ros: invalid duration %q", s)
+		}
+		number := rest[:i]
+		rest = rest[i:]
+
+		// The number is the hours of a clock such as "03:04:05", which always ends the duration
+		if strings.HasPrefix(rest, ":") {
+			clock, err := parseClock(number + rest)
+			if err != nil {
+				return 0, fmt.Errorf("ros: invalid duration %q: %w", s, err)
+			}
+			if total, ok = addDuration(total, clock, negative); !ok {
+				return 0, fmt.Errorf("ros: invalid duration %q: out of range", s)
+			}
+			break
+		}
+
+		// A bare number without unit is a number of seconds
+		unit := time.Second
+		if rest != "" {
+			if unit, rest, ok = cutDurationUnit(rest); !ok {
+				return 0, fmt.Errorf("ros: invalid duration %q: unknown unit", s)
+			}
+		}
+
+		// Add the number of units to the total
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("ros: invalid duration %q: %w", s, err)
+		}
+		units := math.Round(value * float64(unit))
+		if units >= math.MaxInt64 {
+			return 0, fmt.Errorf("ros: invalid duration %q: out of range", s)
+		}
+		if total, ok = addDuration(total, time.Duration(units), negative); !ok {
+			return 0, fmt.Errorf("ros: invalid duration %q: out of range", s)
+		}
+	}
+
+	return Duration(total), nil
+}
+
+/*
+addDuration adds the non-negative duration to the total, or subtracts it if the duration is negative, so that
+math.MinInt64 can be reached. It reports false if the total overflows.
+*/
+func addDuration(total, d time.Duration, negative bool) (time.Duration, bool) {
+	if negative {
+		if total < math.MinInt64+d {
+			return 0, false
+		}
+		return total - d, true
+	}
+	if total > math.MaxInt64-d {
+		return 0, false
+	}
+	return total + d, true
+}
+
+// cutDurationUnit removes a duration unit from the start of s, returning the unit and the rest of s
+func cutDurationUnit(s string) (time.Duration, string, bool) {
+	for _, u := range durationUnits {
+		if strings.HasPrefix(s, u.suffix) {
+			return u.unit, s[len(u.suffix):], true
+		}
+	}
+	return 0, s, false
+}
+
+// parseClock parses a "hh:mm:ss" or "hh:mm:ss.fff" clock
+func parseClock(s string) (time.Duration, error) {
+
+	// Split the clock into hours, minutes and seconds
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid clock %q", s)
+	}
+
+	hours, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || hours >= uint64(math.MaxInt64/time.Hour) {
+		return 0, fmt.Errorf("invalid clock %q", s)
+	}
+	minutes, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid clock %q", s)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || seconds < 0 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid clock %q", s)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(math.Round(seconds*float64(time.Second))), nil
+}
+
+// String returns the duration in RouterOS format, e.g. "1w2d3h4m5s", or "0s" for zero
+func (d Duration) String() string {
+	// Check if the duration is zero
+	if d == 0 {
+		return "0s"
+	}
+
+	var builder strings.Builder
+
+	// Check if the duration is negative, the magnitude is unsigned so that math.MinInt64 does not overflow
+	rest := uint64(d)
+	if d < 0 {
+		builder.WriteByte('-')
+		rest = uint64(-(d + 1)) + 1
+	}
+
+	// Write every non-zero unit from the largest to the smallest
+	for _, u := range []struct {
+		suffix string
+		unit   time.Duration
+	}{{"w", Week}, {"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute}, {"s", time.Second},
+		{"ms", time.Millisecond}, {"us", time.Microsecond}, {"ns", time.Nanosecond}} {
+		if count := rest / uint64(u.unit); count > 0 {
+			builder.WriteString(strconv.FormatUint(count, 10))
+			builder.WriteString(u.suffix)
+			rest -= count * uint64(u.unit)
+		}
+	}
+
+	return builder.String()
+}
+
+// Duration returns the duration as a time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// MarshalText encodes the duration in RouterOS format
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a RouterOS duration
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+// MarshalJSON encodes the duration as a JSON string in RouterOS format
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return marshalString(d.String())
+}
+
+// UnmarshalJSON decodes a RouterOS duration from a JSON string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, d.UnmarshalText)
+}
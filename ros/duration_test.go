@@ -0,0 +1,105 @@
+package ros
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseDuration tests the ParseDuration function with the RouterOS duration formats
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string        // Input value
+		expected time.Duration // Expected result
+		wantErr  bool          // Expected error
+	}{
+		{"1w2d03:04:05", Week + 2*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, false},
+		{"1w2d3h4m5s", Week + 2*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, false},
+		{"00:00:10", 10 * time.Second, false},
+		{"00:00:10.5", 10*time.Second + 500*time.Millisecond, false},
+		{"27:00:00", 27 * time.Hour, false},
+		{"5m30s", 5*time.Minute + 30*time.Second, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"1s500ms", 1500 * time.Millisecond, false},
+		{"1.5s", 1500 * time.Millisecond, false},
+		{"1d", 24 * time.Hour, false},
+		{"30", 30 * time.Second, false},
+		{"", 0, false},
+		{"1x", 0, true},
+		{"abc", 0, true},
+		{"1d00:61:00", 0, true},
+		{"1:2", 0, true},
+		{"-1m", -time.Minute, false},
+		{"-1d00:00:01", -24*time.Hour - time.Second, false},
+		{"-", 0, true},
+		{"--1s", 0, true},
+		{"15250w1d23h47m16s854ms775us808ns", 0, true},
+		{"-15250w1d23h47m16s854ms775us808ns", math.MinInt64, false},
+		{"99999999999999w", 0, true},
+		{"15250w2d", 0, true},
+		{"4294967295:00:00", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseDuration(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.expected, result.Duration())
+		})
+	}
+}
+
+// TestDuration_String tests formatting durations in RouterOS format
+func TestDuration_String(t *testing.T) {
+	tests := []struct {
+		input    time.Duration // Input value
+		expected string        // Expected result
+	}{
+		{0, "0s"},
+		{Week + 2*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, "1w2d3h4m5s"},
+		{90 * time.Minute, "1h30m"},
+		{1500 * time.Millisecond, "1s500ms"},
+		{-time.Minute, "-1m"},
+		{math.MinInt64, "-15250w1d23h47m16s854ms775us808ns"},
+		{math.MaxInt64, "15250w1d23h47m16s854ms775us807ns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Duration(tt.input).String())
+		})
+	}
+}
+
+// TestDuration_RoundTrip tests that negative and extreme durations round-trip through JSON
+func TestDuration_RoundTrip(t *testing.T) {
+	for _, d := range []time.Duration{-time.Minute, -90 * time.Second, math.MinInt64, math.MaxInt64, time.Nanosecond} {
+		data, err := json.Marshal(Duration(d))
+		require.NoError(t, err)
+
+		var decoded Duration
+		require.NoError(t, json.Unmarshal(data, &decoded), string(data))
+		assert.Equal(t, d, decoded.Duration(), string(data))
+	}
+}
+
+// TestDuration_JSON tests that durations round-trip through JSON
+func TestDuration_JSON(t *testing.T) {
+	var d Duration
+	require.NoError(t, json.Unmarshal([]byte(`"2d00:00:01"`), &d))
+	assert.Equal(t, 48*time.Hour+time.Second, d.Duration())
+
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+	assert.Equal(t, `"2d1s"`, string(data))
+
+	var again Duration
+	require.NoError(t, json.Unmarshal(data, &again))
+	assert.Equal(t, d, again)
+
+	assert.Error(t, json.Unmarshal([]byte(`"soon"`), &d))
+}
@@ -0,0 +1,126 @@
+package ros
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+/*
+IPPrefix is a RouterOS address with prefix length such as "192.168.88.1/24".
+Unlike a network prefix the host bits are kept. A bare address decodes as a single-address prefix.
+The zero value is an unset address and encodes as "".
+*/
+type IPPrefix struct {
+	netip.Prefix
+}
+
+// ParseIPPrefix parses a RouterOS address with optional prefix length, an empty string is the zero IPPrefix
+func ParseIPPrefix(s string) (IPPrefix, error) {
+	s = strings.TrimSpace(s)
+
+	// Check if the address is empty
+	if s == "" {
+		return IPPrefix{}, nil
+	}
+
+	// Parse an address with prefix length
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return IPPrefix{}, fmt.Errorf("ros: invalid address %q: %w", s, err)
+		}
+		return IPPrefix{prefix}, nil
+	}
+
+	// Parse a bare address as a single-address prefix
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return IPPrefix{}, fmt.Errorf("ros: invalid address %q: %w", s, err)
+	}
+	return IPPrefix{netip.PrefixFrom(addr, addr.BitLen())}, nil
+}
+
+// String returns the address with prefix length, or "" for the zero IPPrefix
+func (p IPPrefix) String() string {
+	if !p.IsValid() {
+		return ""
+	}
+	return p.Prefix.String()
+}
+
+// MarshalText encodes the address with prefix length
+func (p IPPrefix) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a RouterOS address with optional prefix length
+func (p *IPPrefix) UnmarshalText(text []byte) error {
+	value, err := ParseIPPrefix(string(text))
+	if err != nil {
+		return err
+	}
+	*p = value
+	return nil
+}
+
+// MarshalJSON encodes the address as a JSON string
+func (p IPPrefix) MarshalJSON() ([]byte, error) {
+	return marshalString(p.String())
+}
+
+// UnmarshalJSON decodes a RouterOS address from a JSON string
+func (p *IPPrefix) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, p.UnmarshalText)
+}
+
+// MAC is a RouterOS MAC address, encoded in upper case with colons such as "AA:BB:CC:DD:EE:FF"
+type MAC net.HardwareAddr
+
+// ParseMAC parses a MAC address, an empty string is the nil MAC
+func ParseMAC(s string) (MAC, error) {
+	s = strings.TrimSpace(s)
+
+	// Check if the MAC address is empty
+	if s == "" {
+		return nil, nil
+	}
+
+	// Parse the MAC address
+	addr, err := net.ParseMAC(s)
+	if err != nil {
+		return nil, fmt.Errorf("ros: invalid MAC address %q: %w", s, err)
+	}
+	return MAC(addr), nil
+}
+
+// String returns the MAC address in RouterOS format, or "" for the nil MAC
+func (m MAC) String() string {
+	return strings.ToUpper(net.HardwareAddr(m).String())
+}
+
+// MarshalText encodes the MAC address in RouterOS format
+func (m MAC) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a MAC address
+func (m *MAC) UnmarshalText(text []byte) error {
+	value, err := ParseMAC(string(text))
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+// MarshalJSON encodes the MAC address as a JSON string in RouterOS format
+func (m MAC) MarshalJSON() ([]byte, error) {
+	return marshalString(m.String())
+}
+
+// UnmarshalJSON decodes a MAC address from a JSON string
+func (m *MAC) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, m.UnmarshalText)
+}
@@ -0,0 +1,75 @@
+package ros
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseIPPrefix tests the ParseIPPrefix function with IPv4 and IPv6 addresses
+func TestParseIPPrefix(t *testing.T) {
+	tests := []struct {
+		input    string // Input value
+		expected string // Expected result
+		wantErr  bool   // Expected error
+	}{
+		{"192.168.88.1/24", "192.168.88.1/24", false},
+		{"10.0.0.0/8", "10.0.0.0/8", false},
+		{"10.0.0.1", "10.0.0.1/32", false},
+		{"fe80::1/64", "fe80::1/64", false},
+		{"2001:db8::1", "2001:db8::1/128", false},
+		{"", "", false},
+		{"192.168.88.1/33", "", true},
+		{"router", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseIPPrefix(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.expected, result.String())
+		})
+	}
+}
+
+// TestIPPrefix_JSON tests that addresses round-trip through JSON
+func TestIPPrefix_JSON(t *testing.T) {
+	var prefix IPPrefix
+	require.NoError(t, json.Unmarshal([]byte(`"192.168.88.1/24"`), &prefix))
+	assert.Equal(t, 24, prefix.Bits())
+	assert.Equal(t, "192.168.88.1", prefix.Addr().String())
+
+	data, err := json.Marshal(prefix)
+	require.NoError(t, err)
+	assert.Equal(t, `"192.168.88.1/24"`, string(data))
+
+	data, err = json.Marshal(IPPrefix{})
+	require.NoError(t, err)
+	assert.Equal(t, `""`, string(data))
+}
+
+// TestParseMAC tests the ParseMAC function with valid and invalid MAC addresses
+func TestParseMAC(t *testing.T) {
+	mac, err := ParseMAC("aa:bb:cc:dd:ee:ff")
+	require.NoError(t, err)
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", mac.String())
+
+	mac, err = ParseMAC("")
+	require.NoError(t, err)
+	assert.Nil(t, mac)
+
+	_, err = ParseMAC("aa:bb")
+	assert.Error(t, err)
+}
+
+// TestMAC_JSON tests that MAC addresses round-trip through JSON
+func TestMAC_JSON(t *testing.T) {
+	var mac MAC
+	require.NoError(t, json.Unmarshal([]byte(`"4c:5e:0c:00:00:01"`), &mac))
+
+	data, err := json.Marshal(mac)
+	require.NoError(t, err)
+	assert.Equal(t, `"4C:5E:0C:00:00:01"`, string(data))
+}
@@ -0,0 +1,159 @@
+/*
+Package ros provides Go types for values returned by the RouterOS v7 REST API.
+RouterOS encodes every property as a JSON string, e.g. "true", "1w2d03:04:05", "12.3KiB" or "*1A".
+The types in this package implement json.Marshaler and json.Unmarshaler (and their encoding.Text counterparts)
+so that they decode from and encode to the RouterOS format.
+*/
+package ros
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bool is a RouterOS boolean, decoded from "true", "false", "yes" or "no" and encoded as "yes" or "no"
+type Bool bool
+
+// ParseBool parses a RouterOS boolean, an empty string is false
+func ParseBool(s string) (Bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "yes":
+		return true, nil
+	case "false", "no", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("ros: invalid boolean %q", s)
+}
+
+// String returns "yes" or "no"
+func (b Bool) String() string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// MarshalText encodes the boolean as "yes" or "no"
+func (b Bool) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText decodes a RouterOS boolean
+func (b *Bool) UnmarshalText(text []byte) error {
+	value, err := ParseBool(string(text))
+	if err != nil {
+		return err
+	}
+	*b = value
+	return nil
+}
+
+// MarshalJSON encodes the boolean as the JSON string "yes" or "no"
+func (b Bool) MarshalJSON() ([]byte, error) {
+	return marshalString(b.String())
+}
+
+// UnmarshalJSON decodes a RouterOS boolean from a JSON string or a JSON boolean
+func (b *Bool) UnmarshalJSON(data []byte) error {
+
+	// Accept JSON booleans as well as strings
+	var value bool
+	if string(data) != "null" && json.Unmarshal(data, &value) == nil {
+		*b = Bool(value)
+		return nil
+	}
+
+	return unmarshalString(data, b.UnmarshalText)
+}
+
+// ID is a RouterOS internal item id such as "*1A", the zero value is an unset id and encodes as ""
+type ID uint64
+
+// ParseID parses a RouterOS item id, an empty string is the zero ID
+func ParseID(s string) (ID, error) {
+
+	// Check if the id is empty
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	// Check if the id starts with a star
+	if !strings.HasPrefix(s, "*") {
+		return 0, fmt.Errorf("ros: invalid id %q: missing '*' prefix", s)
+	}
+
+	// Parse the hexadecimal number after the star
+	value, err := strconv.ParseUint(s[1:], 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ros: invalid id %q: %w", s, err)
+	}
+
+	return ID(value), nil
+}
+
+// String returns the id in RouterOS format, e.g. "*1A", or "" for the zero ID
+func (id ID) String() string {
+	if id == 0 {
+		return ""
+	}
+	return "*" + strings.ToUpper(strconv.FormatUint(uint64(id), 16))
+}
+
+// MarshalText encodes the id in RouterOS format
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes a RouterOS item id
+func (id *ID) UnmarshalText(text []byte) error {
+	value, err := ParseID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = value
+	return nil
+}
+
+// MarshalJSON encodes the id as a JSON string in RouterOS format
+func (id ID) MarshalJSON() ([]byte, error) {
+	return marshalString(id.String())
+}
+
+// UnmarshalJSON decodes a RouterOS item id from a JSON string
+func (id *ID) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, id.UnmarshalText)
+}
+
+// marshalString encodes a string as a JSON string
+func marshalString(s string) ([]byte, error) {
+	return json.Marshal(s)
+}
+
+/*
+unmarshalString decodes a JSON string and passes it to parse. JSON null leaves the value untouched and
+JSON numbers are passed as their literal text, since some RouterOS versions return bare numbers.
+*/
+func unmarshalString(data []byte, parse func([]byte) error) error {
+
+	// Check if the value is null
+	if string(data) == "null" {
+		return nil
+	}
+
+	// Pass JSON numbers as their literal text
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err == nil && len(data) > 0 && data[0] != '"' {
+		return parse([]byte(number.String()))
+	}
+
+	// Decode the JSON string
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("ros: expected JSON string, got %s", string(data))
+	}
+
+	return parse([]byte(s))
+}
@@ -0,0 +1,143 @@
+package ros
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseBool tests the ParseBool function with the RouterOS boolean spellings
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		input    string // Input value
+		expected Bool   // Expected result
+		wantErr  bool   // Expected error
+	}{
+		{"true", true, false},
+		{"false", false, false},
+		{"yes", true, false},
+		{"no", false, false},
+		{"YES", true, false},
+		{"", false, false},
+		{"maybe", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseBool(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestBool_JSON tests decoding and encoding a Bool
+func TestBool_JSON(t *testing.T) {
+	var values []Bool
+	require.NoError(t, json.Unmarshal([]byte(`["true","false","yes","no",true,false]`), &values))
+	assert.Equal(t, []Bool{true, false, true, false, true, false}, values)
+
+	data, err := json.Marshal([]Bool{true, false})
+	require.NoError(t, err)
+	assert.Equal(t, `["yes","no"]`, string(data))
+
+	var b Bool
+	assert.Error(t, json.Unmarshal([]byte(`"maybe"`), &b))
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &b))
+}
+
+// TestParseID tests the ParseID function with valid and invalid ids
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		input    string // Input value
+		expected ID     // Expected result
+		wantErr  bool   // Expected error
+	}{
+		{"*1A", 0x1A, false},
+		{"*1a", 0x1A, false},
+		{"*FFFFFFFF", 0xFFFFFFFF, false},
+		{"", 0, false},
+		{"1A", 0, true},
+		{"*", 0, true},
+		{"*XYZ", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseID(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestID_JSON tests decoding and encoding an ID
+func TestID_JSON(t *testing.T) {
+	var id ID
+	require.NoError(t, json.Unmarshal([]byte(`"*1a"`), &id))
+	assert.Equal(t, ID(0x1A), id)
+	assert.Equal(t, "*1A", id.String())
+
+	data, err := json.Marshal(id)
+	require.NoError(t, err)
+	assert.Equal(t, `"*1A"`, string(data))
+
+	data, err = json.Marshal(ID(0))
+	require.NoError(t, err)
+	assert.Equal(t, `""`, string(data))
+}
+
+// TestNull tests that JSON null leaves every type untouched
+func TestNull(t *testing.T) {
+	b, id, d, size := Bool(true), ID(1), Duration(time.Second), Bytes(1)
+
+	for _, target := range []interface{}{&b, &id, &d, &size} {
+		require.NoError(t, json.Unmarshal([]byte(`null`), target))
+	}
+
+	assert.Equal(t, Bool(true), b)
+	assert.Equal(t, ID(1), id)
+	assert.Equal(t, Duration(time.Second), d)
+	assert.Equal(t, Bytes(1), size)
+}
+
+// resource is a typed system/resource item used by the round-trip test
+type resource struct {
+	ID         ID       `json:".id,omitempty"`
+	Uptime     Duration `json:"uptime"`
+	FreeMemory Bytes    `json:"free-memory"`
+	Disabled   Bool     `json:"disabled"`
+	Address    IPPrefix `json:"address"`
+	MACAddress MAC      `json:"mac-address"`
+	About      string   `json:".about,omitempty"`
+}
+
+// TestRoundTrip tests decoding a RouterOS item and encoding it back
+func TestRoundTrip(t *testing.T) {
+	input := `{".id":"*1A","uptime":"1w2d03:04:05","free-memory":"12.5KiB","disabled":"true",
+		"address":"192.168.88.1/24","mac-address":"aa:bb:cc:dd:ee:ff",".about":"managed by script"}`
+
+	var item resource
+	require.NoError(t, json.Unmarshal([]byte(input), &item))
+
+	assert.Equal(t, ID(0x1A), item.ID)
+	assert.Equal(t, 9*24*time.Hour+3*time.Hour+4*time.Minute+5*time.Second, item.Uptime.Duration())
+	assert.Equal(t, Bytes(12800), item.FreeMemory)
+	assert.Equal(t, Bool(true), item.Disabled)
+	assert.Equal(t, "192.168.88.1/24", item.Address.String())
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", item.MACAddress.String())
+	assert.Equal(t, "managed by script", item.About)
+
+	data, err := json.Marshal(item)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{".id":"*1A","uptime":"1w2d3h4m5s","free-memory":"12800","disabled":"yes",
+		"address":"192.168.88.1/24","mac-address":"AA:BB:CC:DD:EE:FF",".about":"managed by script"}`, string(data))
+
+	// Decoding the encoded item gives the same item
+	var again resource
+	require.NoError(t, json.Unmarshal(data, &again))
+	assert.Equal(t, item, again)
+}
@@ -0,0 +1,153 @@
+package routerostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Item is an item of a menu, every property being a string as returned by RouterOS, e.g. {".id": "*1", "disabled": "false"}
+type Item map[string]string
+
+// clone returns a copy of the item
+func (i Item) clone() Item {
+	copied := make(Item, len(i))
+	for name, value := range i {
+		copied[name] = value
+	}
+	return copied
+}
+
+/*
+Fixtures are the menus served by the server by command path. A path holding a JSON array, e.g. "ip/address",
+is a list of items with ids; a path holding a JSON object, e.g. "system/resource", is a single item.
+example:
+
+	{
+	  "ip/address": [{"address": "192.168.88.1/24", "interface": "bridge"}],
+	  "system/resource": {"version": "7.14.3 (stable)", "board-name": "hEX"}
+	}
+*/
+type Fixtures map[string]json.RawMessage
+
+// LoadFixtures reads the fixtures from a JSON file
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err // Return nil and error
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("routerostest: fixtures %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// WithFixtures seeds the server with the fixtures
+func WithFixtures(fixtures Fixtures) Option {
+	return func(s *Server) error {
+		// Seed the paths in order so that the generated ids do not depend on the map order
+		paths := make([]string, 0, len(fixtures))
+		for path := range fixtures {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			if err := s.seedFixture(path, fixtures[path]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithFixtureFile seeds the server with the fixtures of a JSON file
+func WithFixtureFile(path string) Option {
+	return func(s *Server) error {
+		fixtures, err := LoadFixtures(path)
+		if err != nil {
+			return err
+		}
+		return WithFixtures(fixtures)(s)
+	}
+}
+
+// WithMenu seeds the list of items of a path, which may be empty to declare the path only
+func WithMenu(path string, items ...Item) Option {
+	return func(s *Server) error {
+		s.seedMenu(cleanPath(path), items)
+		return nil
+	}
+}
+
+// WithSingleton seeds the single item of a path such as "system/resource"
+func WithSingleton(path string, item Item) Option {
+	return func(s *Server) error {
+		s.singletons[cleanPath(path)] = item.clone()
+		return nil
+	}
+}
+
+// seedFixture seeds the path with the JSON array or object of the fixture
+func (s *Server) seedFixture(path string, fixture json.RawMessage) error {
+	path = cleanPath(path)
+
+	// Check if the fixture is a list of items
+	var items []map[string]interface{}
+	if err := json.Unmarshal(fixture, &items); err == nil {
+		menu := make([]Item, 0, len(items))
+		for _, item := range items {
+			menu = append(menu, toItem(item))
+		}
+		s.seedMenu(path, menu)
+		return nil
+	}
+
+	// Check if the fixture is a single item
+	var item map[string]interface{}
+	if err := json.Unmarshal(fixture, &item); err != nil {
+		return fmt.Errorf("routerostest: fixture %s must be a JSON array or object", path)
+	}
+	s.singletons[path] = toItem(item)
+	return nil
+}
+
+// seedMenu adds the items to the list of the path, generating the missing ids
+func (s *Server) seedMenu(path string, items []Item) {
+	menu := s.menus[path]
+	for _, item := range items {
+		item = item.clone()
+		if item[".id"] == "" {
+			item[".id"] = s.nextID()
+		} else {
+			s.reserveID(item[".id"])
+		}
+		menu = append(menu, item)
+	}
+	if menu == nil {
+		menu = []Item{}
+	}
+	s.menus[path] = menu
+}
+
+// toItem converts the decoded JSON values to RouterOS strings, e.g. true to "true" and 1500 to "1500"
+func toItem(values map[string]interface{}) Item {
+	item := make(Item, len(values))
+	for name, value := range values {
+		switch value := value.(type) {
+		case string:
+			item[name] = value
+		case nil:
+			item[name] = ""
+		case float64, bool:
+			item[name] = fmt.Sprint(value)
+		default:
+			encoded, _ := json.Marshal(value)
+			item[name] = string(encoded)
+		}
+	}
+	return item
+}
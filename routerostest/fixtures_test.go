@@ -0,0 +1,62 @@
+package routerostest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadFixtures tests the seeding of the menus and singletons of a fixture file
+func TestLoadFixtures(t *testing.T) {
+	server := NewServer(t, WithFixtureFile("testdata/router.json"))
+
+	assert.Equal(t, Item{"version": "7.14.3 (stable)", "board-name": "hEX", "cpu-load": "3"},
+		server.singletons["system/resource"])
+
+	interfaces := server.Items("interface")
+	require.Len(t, interfaces, 3)
+	assert.Equal(t, Item{".id": "*1", "name": "ether1", "type": "ether", "mtu": "1500", "running": "true"},
+		interfaces[0])
+
+	// The items without id follow the seeded ids
+	assert.Equal(t, "*B", server.Items("/ip/address/")[0][".id"])
+	assert.Empty(t, server.Items("ip/firewall/filter"))
+	assert.NotNil(t, server.Items("ip/firewall/filter"))
+}
+
+// TestWithMenu tests the seeding of the menus and singletons with Go values
+func TestWithMenu(t *testing.T) {
+	seed := Item{"name": "dhcp", "ranges": "10.0.0.10-10.0.0.20"}
+	server := NewServer(t,
+		WithMenu("ip/pool", seed, Item{".id": "*7", "name": "vpn"}),
+		WithMenu("ip/route"),
+		WithSingleton("system/identity", Item{"name": "MikroTik"}),
+	)
+	seed["name"] = "changed"
+
+	pools := server.Items("ip/pool")
+	require.Len(t, pools, 2)
+	assert.Equal(t, Item{".id": "*1", "name": "dhcp", "ranges": "10.0.0.10-10.0.0.20"}, pools[0])
+	assert.Equal(t, "*7", pools[1][".id"])
+	assert.Empty(t, server.Items("ip/route"))
+	assert.Equal(t, "*8", server.nextID())
+}
+
+// TestLoadFixtures_Errors tests the invalid fixture files
+func TestLoadFixtures_Errors(t *testing.T) {
+	_, err := LoadFixtures("testdata/missing.json")
+	assert.Error(t, err)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`[]`), 0o600))
+	_, err = LoadFixtures(invalid)
+	assert.ErrorContains(t, err, "routerostest: fixtures")
+
+	s := &Server{menus: map[string][]Item{}, singletons: map[string]Item{}}
+	err = WithFixtures(Fixtures{"ip/address": json.RawMessage(`"10.0.0.1"`)})(s)
+	assert.EqualError(t, err, "routerostest: fixture ip/address must be a JSON array or object")
+}
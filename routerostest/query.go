@@ -0,0 +1,113 @@
+package routerostest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// queryNamePattern matches the property names of the condition words, including dot properties such as ".id"
+var queryNamePattern = regexp.MustCompile(`^\.?[a-z0-9][a-z0-9-]*$`)
+
+/*
+matchQuery evaluates the words of a .query stack on the item, as RouterOS does for a print command.
+Every condition pushes its result on the stack, "#" words apply the operators "|", "&", "!", "." and the digits
+to the stack, and the values left on the stack are ANDed together.
+*/
+func matchQuery(item Item, words []string) (bool, error) {
+	var stack []bool
+
+	for _, word := range words {
+
+		// Check if the word is a condition
+		if !strings.HasPrefix(word, "#") {
+			matched, err := matchCondition(item, word)
+			if err != nil {
+				return false, err
+			}
+			stack = append(stack, matched)
+			continue
+		}
+
+		// Apply every operation of the operator word
+		for _, op := range word[1:] {
+			top := len(stack) - 1
+			switch {
+			case (op == '|' || op == '&') && top >= 1:
+				if op == '|' {
+					stack[top-1] = stack[top-1] || stack[top]
+				} else {
+					stack[top-1] = stack[top-1] && stack[top]
+				}
+				stack = stack[:top]
+			case op == '!' && top >= 0:
+				stack[top] = !stack[top]
+			case op == '.' && top >= 0:
+				stack = append(stack, stack[top])
+			case op >= '0' && op <= '9' && int(op-'0') <= top:
+				stack = append(stack, stack[op-'0'])
+			default:
+				return false, fmt.Errorf("invalid query word %q", word)
+			}
+		}
+	}
+
+	// AND the values left on the stack
+	for _, value := range stack {
+		if !value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+/*
+matchCondition evaluates a condition word as RouterOS does: "name=value", "<name=value", ">name=value", "name" or
+"-name". Any other word, such as "mtu<1500" or "comment~^lab", is an error, so that tests catch queries a router
+would reject.
+*/
+func matchCondition(item Item, word string) (bool, error) {
+
+	// Split the word into its operator, property name and value
+	operator, rest := "", word
+	if rest != "" && strings.ContainsRune("-<>", rune(rest[0])) {
+		operator, rest = rest[:1], rest[1:]
+	}
+	name, expected, hasValue := strings.Cut(rest, "=")
+
+	// Check if the property name is valid
+	if !queryNamePattern.MatchString(name) {
+		return false, fmt.Errorf("invalid query word %q", word)
+	}
+
+	value, ok := item[name]
+	switch {
+	case operator == "" && !hasValue:
+		return ok, nil // The item has the property
+	case operator == "-" && !hasValue:
+		return !ok, nil // The item is missing the property
+	case operator == "" && hasValue:
+		return ok && value == expected, nil
+	case operator == "<" && hasValue:
+		return ok && compare(value, expected) < 0, nil
+	case operator == ">" && hasValue:
+		return ok && compare(value, expected) > 0, nil
+	}
+	return false, fmt.Errorf("invalid query word %q", word)
+}
+
+// compare compares the values as numbers if both are numbers, or as strings otherwise
+func compare(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	switch {
+	case errA != nil || errB != nil:
+		return strings.Compare(a, b)
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
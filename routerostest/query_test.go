@@ -0,0 +1,59 @@
+package routerostest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchQuery tests the evaluation of the .query stack
+func TestMatchQuery(t *testing.T) {
+	item := Item{"name": "ether1", "mtu": "1500", "comment": "uplink"}
+
+	tests := []struct {
+		words    []string
+		expected bool
+	}{
+		{nil, true},
+		{[]string{"name=ether1"}, true},
+		{[]string{"name=ether2"}, false},
+		{[]string{"<mtu=9000"}, true},
+		{[]string{">mtu=9000"}, false},
+		{[]string{">mtu=1000"}, true},
+		{[]string{"<missing=1"}, false},
+		{[]string{"comment=up=link"}, false},
+		{[]string{"comment"}, true},
+		{[]string{"-comment"}, false},
+		{[]string{"-disabled"}, true},
+		{[]string{"name=ether2", "name=ether1", "#|"}, true},
+		{[]string{"name=ether1", "mtu=9000", "#&"}, false},
+		{[]string{"comment", "#!"}, false},
+		{[]string{"name=ether1", "mtu=1500"}, true},
+		{[]string{"name=ether1", "#.!"}, false},
+		{[]string{"name=ether2", "mtu=1500", "#0|"}, false},
+	}
+
+	for _, test := range tests {
+		matched, err := matchQuery(item, test.words)
+		assert.NoError(t, err, test.words)
+		assert.Equal(t, test.expected, matched, test.words)
+	}
+}
+
+// TestMatchQuery_Errors tests the invalid .query stacks
+func TestMatchQuery_Errors(t *testing.T) {
+	for _, words := range [][]string{
+		{"#|"}, {"name=a", "#&"}, {"#!"}, {"name=a", "#x"},
+		{"mtu<9000"}, {"mtu>1000"}, {"name~^a"}, {"<mtu"}, {"-name=a"}, {"=a"}, {""}, {"Name=a"},
+	} {
+		_, err := matchQuery(Item{"name": "a"}, words)
+		assert.Error(t, err, words)
+	}
+}
+
+// TestCompare tests the comparison of numbers and strings
+func TestCompare(t *testing.T) {
+	assert.Equal(t, -1, compare("9", "10"))
+	assert.Equal(t, 1, compare("b", "a"))
+	assert.Equal(t, 0, compare("1.0", "1"))
+}
@@ -0,0 +1,451 @@
+/*
+Package routerostest provides an in-memory fake of the RouterOS v7 REST API for integration tests.
+NewServer starts an httptest.Server serving /rest/... like a router: GET lists a menu or looks up an item by id or
+name, PUT creates an item with a generated "*N" id, PATCH updates it, DELETE removes it and POST runs "print" with
+.proplist and .query. Requests are authenticated with Basic Auth and errors are answered with RouterOS-shaped
+bodies such as {"error": 404, "message": "Not Found"}. The menus are seeded from fixtures.
+example:
+server := routerostest.NewServer(t, routerostest.WithFixtureFile("testdata/router.json"))
+client, err := server.NewClient()
+*/
+package routerostest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
+
+// Default credentials of the server
+const (
+	DefaultUsername = "admin" // DefaultUsername is the username accepted by default
+	DefaultPassword = ""      // DefaultPassword is the password accepted by default, empty as on a new router
+)
+
+// Option configures the server
+type Option func(*Server) error
+
+// WithCredentials sets the username and password accepted by the server
+func WithCredentials(username, password string) Option {
+	return func(s *Server) error {
+		s.username = username // Set the username
+		s.password = password // Set the password
+		return nil
+	}
+}
+
+// Server is an in-memory fake of the RouterOS v7 REST API, safe for concurrent use
+type Server struct {
+	*httptest.Server
+
+	username string // Username accepted by the server
+	password string // Password accepted by the server
+
+	mu         sync.Mutex        // Lock of the menus
+	menus      map[string][]Item // Lists of items by command path, e.g. "ip/address"
+	singletons map[string]Item   // Single items by command path, e.g. "system/resource"
+	lastID     int               // Last id generated or seeded, ids are "*" followed by hexadecimal digits
+}
+
+/*
+NewServer starts a fake router configured with the options, which is closed when the test ends.
+The test fails if an option is invalid, e.g. a fixture file that cannot be read.
+*/
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	s := &Server{
+		username:   DefaultUsername,
+		password:   DefaultPassword,
+		menus:      make(map[string][]Item),
+		singletons: make(map[string]Item),
+	}
+
+	// Apply the options
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			t.Fatalf("routerostest: %v", err)
+		}
+	}
+
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// NewClient creates a client of the server with its credentials and the options
+func (s *Server) NewClient(opts ...routeros.Option) (*routeros.Client, error) {
+	opts = append([]routeros.Option{routeros.WithCredentials(s.username, s.password)}, opts...)
+	return routeros.NewClient(s.URL, opts...)
+}
+
+// Items returns a copy of the items of the menu, e.g. to check the changes made by the code under test
+func (s *Server) Items(path string) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]Item, 0, len(s.menus[cleanPath(path)]))
+	for _, item := range s.menus[cleanPath(path)] {
+		items = append(items, item.clone())
+	}
+	return items
+}
+
+// ServeHTTP answers the REST request like a router
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	// Check the credentials
+	if username, password, ok := r.BasicAuth(); !ok || username != s.username || password != s.password {
+		writeError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	// Check if the path is a REST path
+	segments, ok := restSegments(r.URL)
+	if !ok {
+		writeError(w, http.StatusNotFound, "")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.Join(segments, "/")
+	switch r.Method {
+	case http.MethodGet:
+		s.get(w, r, segments, path)
+	case http.MethodPut:
+		s.put(w, r, path)
+	case http.MethodPatch:
+		s.patch(w, r, segments, path)
+	case http.MethodDelete:
+		s.delete(w, segments)
+	case http.MethodPost:
+		s.post(w, r, segments)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "")
+	}
+}
+
+// get answers a singleton, the items of a menu matching the GET filters, or a single item
+func (s *Server) get(w http.ResponseWriter, r *http.Request, segments []string, path string) {
+	query := r.URL.Query()
+	proplist := splitProplist(query.Get(".proplist"))
+
+	// Check if the path is a single item such as "system/resource"
+	if item, ok := s.singletons[path]; ok {
+		writeJSON(w, http.StatusOK, project(item, proplist))
+		return
+	}
+
+	// Check if the path is a menu
+	if menu, ok := s.menus[path]; ok {
+		items := []Item{}
+		for _, item := range menu {
+			if matchFilters(item, query) {
+				items = append(items, project(item, proplist))
+			}
+		}
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+
+	// Check if the path is an item of a menu
+	_, index, found := s.lookup(segments)
+	if found == nil {
+		writeNoSuchCommand(w, segments)
+		return
+	}
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "")
+		return
+	}
+	writeJSON(w, http.StatusOK, project(found[index], proplist))
+}
+
+// put creates an item in the menu with a generated id and answers the created item
+func (s *Server) put(w http.ResponseWriter, r *http.Request, path string) {
+
+	// Check if the path is a menu
+	if _, ok := s.menus[path]; !ok {
+		writeNoSuchCommand(w, strings.Split(path, "/"))
+		return
+	}
+
+	values, ok := decodeObject(w, r)
+	if !ok {
+		return
+	}
+	delete(values, ".id")
+	values[".id"] = s.nextID()
+
+	s.menus[path] = append(s.menus[path], values)
+	writeJSON(w, http.StatusCreated, values)
+}
+
+// patch updates the properties of an item or a singleton and answers the updated item
+func (s *Server) patch(w http.ResponseWriter, r *http.Request, segments []string, path string) {
+
+	// Check if the path is a single item
+	if item, ok := s.singletons[path]; ok {
+		values, ok := decodeObject(w, r)
+		if !ok {
+			return
+		}
+		update(item, values)
+		writeJSON(w, http.StatusOK, item)
+		return
+	}
+
+	// Check if the path is an item of a menu
+	_, index, menu := s.lookup(segments)
+	if menu == nil {
+		writeNoSuchCommand(w, segments)
+		return
+	}
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "")
+		return
+	}
+
+	values, ok := decodeObject(w, r)
+	if !ok {
+		return
+	}
+	update(menu[index], values)
+	writeJSON(w, http.StatusOK, menu[index])
+}
+
+// delete removes an item of a menu and answers no content
+func (s *Server) delete(w http.ResponseWriter, segments []string) {
+
+	// Check if the path is an item of a menu
+	path, index, menu := s.lookup(segments)
+	if menu == nil {
+		writeNoSuchCommand(w, segments)
+		return
+	}
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "")
+		return
+	}
+
+	s.menus[path] = append(menu[:index:index], menu[index+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// post runs the print command of a menu with the .proplist and .query of the payload
+func (s *Server) post(w http.ResponseWriter, r *http.Request, segments []string) {
+	last := len(segments) - 1
+	menu, ok := s.menus[strings.Join(segments[:last], "/")]
+
+	// Check if the command is a print command of a menu
+	if !ok || segments[last] != "print" {
+		writeNoSuchCommand(w, segments)
+		return
+	}
+
+	// Decode the payload, which may be empty
+	var payload struct {
+		Proplist interface{} `json:".proplist"` // Properties as an array or a comma-separated string
+		Query    []string    `json:".query"`    // Words of the .query stack
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "JSON parsing error: "+err.Error())
+			return
+		}
+	}
+	proplist, err := proplistOf(payload.Proplist)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items := []Item{}
+	for _, item := range menu {
+		matched, err := matchQuery(item, payload.Query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if matched {
+			items = append(items, project(item, proplist))
+		}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+/*
+lookup resolves the segments of an item path such as ["ip", "address", "*1"] or ["interface", "ether1"] to the path
+and items of its menu and the index of the item, found by id or by name. The menu is nil if the parent path is not a
+menu and the index is -1 if no item matches.
+*/
+func (s *Server) lookup(segments []string) (string, int, []Item) {
+	if len(segments) < 2 {
+		return "", -1, nil
+	}
+
+	last := len(segments) - 1
+	path := strings.Join(segments[:last], "/")
+	menu, ok := s.menus[path]
+	if !ok {
+		return "", -1, nil
+	}
+	key := segments[last]
+	for i, item := range menu {
+		if item[".id"] == key || item["name"] == key {
+			return path, i, menu
+		}
+	}
+	return path, -1, menu
+}
+
+// nextID generates the id of a new item
+func (s *Server) nextID() string {
+	s.lastID++
+	return fmt.Sprintf("*%X", s.lastID)
+}
+
+// reserveID makes sure that the generated ids follow a seeded id such as "*1A"
+func (s *Server) reserveID(id string) {
+	if n, err := strconv.ParseInt(strings.TrimPrefix(id, "*"), 16, 64); err == nil && int(n) > s.lastID {
+		s.lastID = int(n)
+	}
+}
+
+// restSegments returns the unescaped segments of the path after /rest/, e.g. ["interface", "wireguard", "wg/0"]
+func restSegments(u *url.URL) ([]string, bool) {
+	escaped := strings.TrimPrefix(u.EscapedPath(), "/rest/")
+	if escaped == u.EscapedPath() {
+		return nil, false
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(escaped, "/") {
+		if segment == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+		segments = append(segments, unescaped)
+	}
+	return segments, len(segments) > 0
+}
+
+// cleanPath trims the slashes of a command path such as "/ip/address/"
+func cleanPath(path string) string {
+	return strings.Trim(path, "/")
+}
+
+// decodeObject decodes the JSON object of the request body, answering a RouterOS error if it is invalid
+func decodeObject(w http.ResponseWriter, r *http.Request) (Item, bool) {
+	var values map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON parsing error: "+err.Error())
+		return nil, false
+	}
+	return toItem(values), true
+}
+
+// update sets the properties of the item, the id cannot be changed
+func update(item, values Item) {
+	for name, value := range values {
+		if name != ".id" {
+			item[name] = value
+		}
+	}
+}
+
+// matchFilters checks if the item has every property of the GET filters
+func matchFilters(item Item, query url.Values) bool {
+	for name, values := range query {
+		if strings.HasPrefix(name, ".") && name != ".id" {
+			continue
+		}
+		for _, value := range values {
+			if item[name] != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// project returns the properties of the item listed in the proplist, or the whole item if the proplist is empty
+func project(item Item, proplist []string) Item {
+	if len(proplist) == 0 {
+		return item
+	}
+	projected := make(Item, len(proplist))
+	for _, name := range proplist {
+		if value, ok := item[name]; ok {
+			projected[name] = value
+		}
+	}
+	return projected
+}
+
+// proplistOf returns the properties of a .proplist given as an array or a comma-separated string
+func proplistOf(value interface{}) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return splitProplist(value), nil
+	case []interface{}:
+		proplist := make([]string, 0, len(value))
+		for _, name := range value {
+			name, ok := name.(string)
+			if !ok {
+				return nil, errors.New("invalid .proplist")
+			}
+			proplist = append(proplist, name)
+		}
+		return proplist, nil
+	}
+	return nil, errors.New("invalid .proplist")
+}
+
+// splitProplist splits a comma-separated .proplist
+func splitProplist(proplist string) []string {
+	var names []string
+	for _, name := range strings.Split(proplist, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// writeNoSuchCommand answers the error of a path that is not a menu, an item or a command
+func writeNoSuchCommand(w http.ResponseWriter, segments []string) {
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("no such command or directory (%s)", segments[len(segments)-1]))
+}
+
+// writeError answers a RouterOS error body such as {"error": 404, "message": "Not Found"}
+func writeError(w http.ResponseWriter, status int, detail string) {
+	body := map[string]interface{}{"error": status, "message": http.StatusText(status)}
+	if detail != "" {
+		body["detail"] = detail
+	}
+	writeJSON(w, status, body)
+}
+
+// writeJSON answers the JSON encoding of the value
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
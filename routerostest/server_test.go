@@ -0,0 +1,182 @@
+package routerostest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+)
+
+// address is an item of ip/address decoded by the typed requests
+type address struct {
+	ID        string `json:".id"`
+	Address   string `json:"address"`
+	Interface string `json:"interface"`
+	Disabled  string `json:"disabled"`
+}
+
+// newTestClient starts a server with the fixture file and returns a client of it
+func newTestClient(t *testing.T, opts ...Option) (*Server, *routeros.Client) {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	server := NewServer(t, append([]Option{WithFixtureFile("testdata/router.json")}, opts...)...)
+	client, err := server.NewClient()
+	require.NoError(t, err)
+	return server, client
+}
+
+// TestServer_Get tests the lists, the GET filters and proplist, the lookups by id or name and the singletons
+func TestServer_Get(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+
+	interfaces, err := routeros.PrintAs[[]map[string]string](ctx, client, "interface")
+	require.NoError(t, err)
+	assert.Len(t, interfaces, 3)
+
+	filtered, err := routeros.PrintAs[[]map[string]string](ctx, client,
+		routeros.NewPath("interface").Filter("type", "ether").Proplist("name").String())
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"name": "ether1"}, {"name": "ether2"}}, filtered)
+
+	byID, err := routeros.PrintAs[map[string]string](ctx, client, "interface/*2")
+	require.NoError(t, err)
+	assert.Equal(t, "ether2", byID["name"])
+
+	byName, err := routeros.PrintAs[map[string]string](ctx, client, "interface/bridge")
+	require.NoError(t, err)
+	assert.Equal(t, "*A", byName[".id"])
+
+	resource, err := routeros.PrintAs[map[string]string](ctx, client, "system/resource")
+	require.NoError(t, err)
+	assert.Equal(t, "hEX", resource["board-name"])
+}
+
+// TestServer_AddSetRemove tests the creation, update and removal of an item
+func TestServer_AddSetRemove(t *testing.T) {
+	server, client := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := routeros.AddAs[address](ctx, client, "ip/address",
+		[]byte(`{"address":"10.0.0.1/24","interface":"ether1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, address{ID: "*C", Address: "10.0.0.1/24", Interface: "ether1"}, created)
+
+	updated, err := routeros.SetAs[address](ctx, client, "ip/address/"+created.ID,
+		[]byte(`{"disabled":true,".id":"*99"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "true", updated.Disabled)
+	assert.Equal(t, "*C", updated.ID)
+
+	_, err = client.Remove(ctx, "ip/address/"+created.ID)
+	require.NoError(t, err)
+	assert.Len(t, server.Items("ip/address"), 1)
+
+	// A removed item cannot be found anymore
+	_, err = client.Remove(ctx, "ip/address/"+created.ID)
+	assert.True(t, routeros.IsNotFound(err))
+	_, err = client.Set(ctx, "ip/address/"+created.ID, []byte(`{}`))
+	assert.True(t, routeros.IsNotFound(err))
+	_, err = client.Print(ctx, "ip/address/"+created.ID)
+	assert.True(t, routeros.IsNotFound(err))
+}
+
+// TestServer_SetSingleton tests the update of a singleton
+func TestServer_SetSingleton(t *testing.T) {
+	_, client := newTestClient(t, WithSingleton("system/identity", Item{"name": "MikroTik"}))
+	ctx := context.Background()
+
+	_, err := client.SetPayload(ctx, "system/identity", map[string]string{"name": "core-1"})
+	require.NoError(t, err)
+
+	identity, err := routeros.PrintAs[map[string]string](ctx, client, "system/identity")
+	require.NoError(t, err)
+	assert.Equal(t, "core-1", identity["name"])
+}
+
+// TestServer_Print tests the print command with .proplist and .query
+func TestServer_Print(t *testing.T) {
+	_, client := newTestClient(t)
+
+	query := routeros.NewQuery().Where("type", "ether").Or("comment", "lan").Less("mtu", "9000").Proplist("name")
+	result, err := client.RunQuery(context.Background(), "interface/print", query)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "ether1"},
+		map[string]interface{}{"name": "bridge"},
+	}, result)
+
+	result, err = client.RunFilter(context.Background(), "interface/print", `running="false"`)
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	result, err = client.Run(context.Background(), "interface/print", nil)
+	require.NoError(t, err)
+	assert.Len(t, result, 3)
+}
+
+// TestServer_Errors tests the RouterOS error bodies
+func TestServer_Errors(t *testing.T) {
+	server, client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		call   func() (interface{}, error)
+		status int
+		detail string
+	}{
+		{"unknown menu", func() (interface{}, error) { return client.Print(ctx, "ip/unknown") },
+			http.StatusBadRequest, "no such command or directory (unknown)"},
+		{"unknown command", func() (interface{}, error) { return client.Run(ctx, "interface/monitor", nil) },
+			http.StatusBadRequest, "no such command or directory (monitor)"},
+		{"add to an item", func() (interface{}, error) { return client.Add(ctx, "interface/*1", []byte(`{}`)) },
+			http.StatusBadRequest, "no such command or directory (*1)"},
+		{"invalid JSON", func() (interface{}, error) { return client.Add(ctx, "ip/address", []byte(`{`)) },
+			http.StatusBadRequest, "JSON parsing error: unexpected EOF"},
+		{"invalid query", func() (interface{}, error) {
+			return client.Run(ctx, "interface/print", []byte(`{".query":["#|"]}`))
+		}, http.StatusBadRequest, `invalid query word "#|"`},
+		{"comparison after the name", func() (interface{}, error) {
+			return client.Run(ctx, "interface/print", []byte(`{".query":["mtu<9000"]}`))
+		}, http.StatusBadRequest, `invalid query word "mtu<9000"`},
+		{"regular expression", func() (interface{}, error) {
+			return client.Run(ctx, "interface/print", []byte(`{".query":["name~^ether"]}`))
+		}, http.StatusBadRequest, `invalid query word "name~^ether"`},
+	}
+
+	for _, test := range tests {
+		_, err := test.call()
+		var apiErr *routeros.APIError
+		require.ErrorAs(t, err, &apiErr, test.name)
+		assert.Equal(t, test.status, apiErr.StatusCode, test.name)
+		assert.Equal(t, test.status, apiErr.Code, test.name)
+		assert.Equal(t, "Bad Request", apiErr.Message, test.name)
+		assert.Equal(t, test.detail, apiErr.Detail, test.name)
+	}
+
+	// The credentials are checked
+	unauthorized, err := routeros.NewClient(server.URL, routeros.WithCredentials("admin", "wrong"))
+	require.NoError(t, err)
+	_, err = unauthorized.Print(ctx, "interface")
+	assert.True(t, routeros.IsUnauthorized(err))
+}
+
+// TestServer_Credentials tests the credentials of the server
+func TestServer_Credentials(t *testing.T) {
+	server := NewServer(t, WithCredentials("api", "s3cr3t"), WithMenu("ip/route"))
+
+	client, err := server.NewClient()
+	require.NoError(t, err)
+	result, err := client.Print(context.Background(), "ip/route")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{}, result)
+
+	response, err := http.Get(server.URL + "/rest/ip/route")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
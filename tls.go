@@ -0,0 +1,154 @@
+package routerosv7_restfull_api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// tlsOptions holds the TLS settings collected from the TLS options, applied on top of WithTLSConfig
+type tlsOptions struct {
+	rootCAs            *x509.CertPool    // Certificate authorities trusted to verify the router's certificate
+	certificates       []tls.Certificate // Client certificates presented to the router
+	serverName         string            // Server name used to verify the router's certificate
+	minVersion         uint16            // Minimum TLS version
+	insecureSkipVerify bool              // Whether the router's certificate is not verified
+	knownRouters       *KnownRouters     // Store pinning the router's certificate
+}
+
+// WithRootCAsFile trusts the certificate authorities of a PEM file to verify the router's certificate
+func WithRootCAsFile(path string) Option {
+	return func(o *clientOptions) error {
+		// Read the PEM file
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("WithRootCAsFile: %w", err)
+		}
+		return addRootCAs(o, pem, "WithRootCAsFile")
+	}
+}
+
+/*
+WithRootCAsPEM trusts the PEM encoded certificate authorities to verify the router's certificate,
+e.g. the certificate of the internal CA or the self-signed certificate of the router's www-ssl service
+*/
+func WithRootCAsPEM(pem []byte) Option {
+	return func(o *clientOptions) error {
+		return addRootCAs(o, pem, "WithRootCAsPEM")
+	}
+}
+
+// addRootCAs adds the PEM encoded certificates to the root CA pool of the options
+func addRootCAs(o *clientOptions, pem []byte, option string) error {
+	// Create the pool on first use
+	if o.tls.rootCAs == nil {
+		o.tls.rootCAs = x509.NewCertPool()
+	}
+
+	// Check if the PEM data contains at least one certificate
+	if !o.tls.rootCAs.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("%s: no certificate found in PEM data", option)
+	}
+	return nil
+}
+
+// WithClientCertificateFiles presents the certificate and key of PEM files to routers requiring mutual TLS
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	return func(o *clientOptions) error {
+		// Load the certificate and its key
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("WithClientCertificateFiles: %w", err)
+		}
+		o.tls.certificates = append(o.tls.certificates, certificate) // Add the certificate
+		return nil
+	}
+}
+
+// WithClientCertificatePEM presents the PEM encoded certificate and key to routers requiring mutual TLS
+func WithClientCertificatePEM(certPEM, keyPEM []byte) Option {
+	return func(o *clientOptions) error {
+		// Parse the certificate and its key
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("WithClientCertificatePEM: %w", err)
+		}
+		o.tls.certificates = append(o.tls.certificates, certificate) // Add the certificate
+		return nil
+	}
+}
+
+// WithServerName sets the name used to verify the router's certificate, e.g. when connecting by IP address
+func WithServerName(serverName string) Option {
+	return func(o *clientOptions) error {
+		// Check if the server name is empty
+		if serverName == "" {
+			return errors.New("WithServerName: empty server name")
+		}
+		o.tls.serverName = serverName // Set the server name
+		return nil
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version, one of tls.VersionTLS10 to tls.VersionTLS13
+func WithMinTLSVersion(version uint16) Option {
+	return func(o *clientOptions) error {
+		// Check if the version is a known TLS version
+		if version < tls.VersionTLS10 || version > tls.VersionTLS13 {
+			return fmt.Errorf("WithMinTLSVersion: unsupported TLS version 0x%04x", version)
+		}
+		o.tls.minVersion = version // Set the minimum version
+		return nil
+	}
+}
+
+/*
+WithInsecureSkipVerify disables the verification of the router's certificate.
+This makes the connection vulnerable to man-in-the-middle attacks, a warning is logged when the client is
+created. Prefer WithRootCAsPEM with the router's certificate.
+*/
+func WithInsecureSkipVerify() Option {
+	return func(o *clientOptions) error {
+		o.tls.insecureSkipVerify = true // Skip the verification
+		return nil
+	}
+}
+
+/*
+newTLSConfig returns the TLS configuration from WithTLSConfig, or an empty one, with the TLS options applied.
+The endpoint is the router whose certificate is pinned when a known-routers store is set.
+*/
+func newTLSConfig(options clientOptions, endpoint Endpoint) *tls.Config {
+
+	// Start from the TLS configuration provided by the caller if any
+	config := &tls.Config{}
+	if options.tlsConfig != nil {
+		config = options.tlsConfig.Clone()
+	}
+
+	// Apply the TLS options
+	if options.tls.rootCAs != nil {
+		config.RootCAs = options.tls.rootCAs
+	}
+	if len(options.tls.certificates) > 0 {
+		config.Certificates = append(config.Certificates, options.tls.certificates...)
+	}
+	if options.tls.serverName != "" {
+		config.ServerName = options.tls.serverName
+	}
+	if options.tls.minVersion != 0 {
+		config.MinVersion = options.tls.minVersion
+	}
+	if options.tls.insecureSkipVerify {
+		loggerOrDiscard(options.logger).Warn("routeros: TLS certificate verification is disabled, " +
+			"connections are vulnerable to man-in-the-middle attacks")
+		config.InsecureSkipVerify = true
+	}
+	if options.tls.knownRouters != nil {
+		pinCertificate(config, options.tls.knownRouters, endpoint.WithScheme(httpsProtocol).Address())
+	}
+
+	return config
+}
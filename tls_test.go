@@ -0,0 +1,225 @@
+package routerosv7_restfull_api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTLSServer creates a TLS server answering an empty list, configured with the given function
+func setupTLSServer(t *testing.T, configure func(config *tls.Config)) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	server.TLS = &tls.Config{}
+	if configure != nil {
+		configure(server.TLS)
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// serverCertificatePEM returns the PEM encoded certificate of a TLS server
+func serverCertificatePEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// generateClientCertificate generates a self-signed client certificate and key, returned as PEM
+func generateClientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "api-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// TestWithRootCAsPEM tests that the router's certificate is verified against the provided CA
+func TestWithRootCAsPEM(t *testing.T) {
+	server := setupTLSServer(t, nil)
+
+	// The self-signed certificate of the server is rejected by default
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+
+	var tlsErr *TLSError
+	assert.True(t, errors.As(err, &tlsErr), "error: %v", err)
+
+	// The certificate is accepted once trusted
+	client, err = NewClient(server.URL, WithRootCAsPEM(serverCertificatePEM(server)))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+}
+
+// TestWithRootCAsFile tests that the CA is loaded from a PEM file
+func TestWithRootCAsFile(t *testing.T) {
+	server := setupTLSServer(t, nil)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, serverCertificatePEM(server), 0o600))
+
+	client, err := NewClient(server.URL, WithRootCAsFile(path))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+}
+
+// TestWithServerName tests that the server name overrides the host when verifying the certificate
+func TestWithServerName(t *testing.T) {
+	server := setupTLSServer(t, nil)
+	roots := WithRootCAsPEM(serverCertificatePEM(server))
+
+	// The certificate of the test server is valid for example.com
+	client, err := NewClient(server.URL, roots, WithServerName("example.com"))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+
+	client, err = NewClient(server.URL, roots, WithServerName("router.lan"))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.Error(t, err)
+}
+
+// TestWithClientCertificatePEM tests that the client certificate is presented to a router requiring mutual TLS
+func TestWithClientCertificatePEM(t *testing.T) {
+	certPEM, keyPEM := generateClientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(certPEM))
+	server := setupTLSServer(t, func(config *tls.Config) {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = clientCAs
+	})
+	roots := WithRootCAsPEM(serverCertificatePEM(server))
+
+	// The request is rejected without a client certificate
+	client, err := NewClient(server.URL, roots)
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.Error(t, err)
+
+	// The request succeeds with the client certificate
+	client, err = NewClient(server.URL, roots, WithClientCertificatePEM(certPEM, keyPEM))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+
+	// The certificate can be loaded from files too
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.pem"), certPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.key"), keyPEM, 0o600))
+	client, err = NewClient(server.URL, roots,
+		WithClientCertificateFiles(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+}
+
+// TestWithMinTLSVersion tests that routers below the minimum TLS version are rejected
+func TestWithMinTLSVersion(t *testing.T) {
+	server := setupTLSServer(t, func(config *tls.Config) {
+		config.MaxVersion = tls.VersionTLS12
+	})
+	roots := WithRootCAsPEM(serverCertificatePEM(server))
+
+	client, err := NewClient(server.URL, roots, WithMinTLSVersion(tls.VersionTLS12))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+
+	client, err = NewClient(server.URL, roots, WithMinTLSVersion(tls.VersionTLS13))
+	require.NoError(t, err)
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.Error(t, err)
+}
+
+// TestWithInsecureSkipVerify tests that verification is skipped and that a warning is logged
+func TestWithInsecureSkipVerify(t *testing.T) {
+	server := setupTLSServer(t, nil)
+
+	// Capture the log output
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
+	client, err := NewClient(server.URL, WithInsecureSkipVerify(), WithLogger(logger))
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "TLS certificate verification is disabled")
+
+	_, err = client.Print(context.Background(), "ip/address")
+	assert.NoError(t, err)
+}
+
+// TestTLSOptions_AppliedOnTopOfTLSConfig tests that the TLS options override WithTLSConfig whatever their order
+func TestTLSOptions_AppliedOnTopOfTLSConfig(t *testing.T) {
+	client, err := NewClient("router.lan",
+		WithServerName("router.internal"),
+		WithTLSConfig(&tls.Config{ServerName: "other", MinVersion: tls.VersionTLS10}),
+		WithMinTLSVersion(tls.VersionTLS12),
+	)
+	require.NoError(t, err)
+
+	config := client.httpClient.Transport.(*http.Transport).TLSClientConfig
+	assert.Equal(t, "router.internal", config.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.False(t, config.InsecureSkipVerify)
+}
+
+// TestTLSOptions_Invalid tests that invalid TLS options are rejected by NewClient
+func TestTLSOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string // Test case name
+		option Option // Option under test
+	}{
+		{"Missing CA file", WithRootCAsFile(filepath.Join(t.TempDir(), "missing.pem"))},
+		{"Invalid CA PEM", WithRootCAsPEM([]byte("not a certificate"))},
+		{"Missing client certificate", WithClientCertificateFiles("missing.pem", "missing.key")},
+		{"Invalid client certificate", WithClientCertificatePEM([]byte("cert"), []byte("key"))},
+		{"Empty server name", WithServerName("")},
+		{"Unknown TLS version", WithMinTLSVersion(0x0200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient("router.lan", tt.option)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Package tracing traces the requests of a RouterOS client with OpenTelemetry.
+Middleware returns a client middleware creating a client span per request, as a child of the span of the request
+context, with the router host, the verb, the command path, the HTTP status and the RouterOS error message.
+example:
+routerosv7_restfull_api.NewClient("192.168.88.1", routerosv7_restfull_api.WithMiddleware(tracing.Middleware()))
+*/
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer
+const ScopeName = "github.com/sumitroajiprabowo/routerosv7-restfull-api/tracing"
+
+// Attribute keys of the spans
+const (
+	ServerAddressKey = attribute.Key("server.address")            // ServerAddressKey is the host of the router
+	ServerPortKey    = attribute.Key("server.port")               // ServerPortKey is the port of the router, if explicit
+	HTTPMethodKey    = attribute.Key("http.request.method")       // HTTPMethodKey is the HTTP method of the request
+	HTTPStatusKey    = attribute.Key("http.response.status_code") // HTTPStatusKey is the HTTP status of the response
+	VerbKey          = attribute.Key("routeros.verb")             // VerbKey is the verb, e.g. "print" or "add"
+	CommandKey       = attribute.Key("routeros.command")          // CommandKey is the command path, e.g. "ip/address"
+	ErrorMessageKey  = attribute.Key("routeros.error.message")    // ErrorMessageKey is the RouterOS error message
+)
+
+// config holds the settings collected from the options
+type config struct {
+	tracerProvider trace.TracerProvider // Provider of the tracer, the global provider by default
+}
+
+// Option configures the tracing middleware
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer instead of the global provider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider // Set the tracer provider
+	}
+}
+
+/*
+Middleware returns a client middleware creating a span named "routeros <verb>" for every request.
+The span is a child of the span of the request context and its context is passed on to the inner middlewares.
+A failed request sets the status of the span to Error and records the error.
+*/
+func Middleware(opts ...Option) routeros.Middleware {
+
+	// Apply the options
+	cfg := config{tracerProvider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.tracerProvider.Tracer(ScopeName)
+
+	return func(next routeros.Doer) routeros.Doer {
+		return routeros.DoerFunc(func(ctx context.Context, request *routeros.Request) (*routeros.Response, error) {
+			ctx, span := tracer.Start(ctx, "routeros "+request.Verb(),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(request)...),
+			)
+			defer span.End()
+
+			response, err := next.Do(ctx, request)
+
+			// Record the status of the response
+			if response != nil {
+				span.SetAttributes(HTTPStatusKey.Int(response.StatusCode))
+			}
+
+			// Check if the request failed
+			if err != nil {
+				var apiErr *routeros.APIError
+				if errors.As(err, &apiErr) {
+					span.SetAttributes(HTTPStatusKey.Int(apiErr.StatusCode))
+					if message := errorMessage(apiErr); message != "" {
+						span.SetAttributes(ErrorMessageKey.String(message))
+					}
+				}
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return response, err
+		})
+	}
+}
+
+// requestAttributes returns the attributes of the span of the request
+func requestAttributes(request *routeros.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		HTTPMethodKey.String(request.Method),
+		VerbKey.String(request.Verb()),
+		CommandKey.String(request.Command),
+	}
+
+	// Add the host and port of the router
+	endpoint, err := routeros.ParseEndpoint(request.Host)
+	if err != nil {
+		return append(attrs, ServerAddressKey.String(request.Host))
+	}
+	attrs = append(attrs, ServerAddressKey.String(endpoint.Host))
+	if port, err := strconv.Atoi(endpoint.Port); err == nil {
+		attrs = append(attrs, ServerPortKey.Int(port))
+	}
+	return attrs
+}
+
+// errorMessage returns the RouterOS error message of the API error, its detail if any or its message otherwise
+func errorMessage(apiErr *routeros.APIError) string {
+	if apiErr.Detail != "" {
+		return apiErr.Detail
+	}
+	return apiErr.Message
+}
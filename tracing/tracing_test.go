@@ -0,0 +1,164 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	routeros "github.com/sumitroajiprabowo/routerosv7-restfull-api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupTracing creates a tracer provider recording the spans in memory
+func setupTracing(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider, exporter
+}
+
+// newTracedClient creates a client for a server answering the status and body, traced with the provider
+func newTracedClient(t *testing.T, provider trace.TracerProvider, status int, body string,
+	opts ...routeros.Option) (*routeros.Client, *httptest.Server) {
+	t.Helper() // This line is needed to tell the test suite that this method is a helper method
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	opts = append([]routeros.Option{routeros.WithMiddleware(Middleware(WithTracerProvider(provider)))}, opts...)
+	client, err := routeros.NewClient(strings.TrimPrefix(server.URL, "http://"), opts...)
+	require.NoError(t, err)
+	return client, server
+}
+
+// attributes returns the attributes of the span as a map
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+// TestMiddleware_Success tests the span of a successful request
+func TestMiddleware_Success(t *testing.T) {
+	provider, exporter := setupTracing(t)
+	client, server := newTracedClient(t, provider, http.StatusOK, `[]`)
+
+	_, err := client.Print(context.Background(), "ip/address")
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "routeros print", span.Name)
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, codes.Unset, span.Status.Code)
+	assert.Equal(t, ScopeName, span.InstrumentationLibrary.Name)
+
+	port, err := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+	require.NoError(t, err)
+
+	attrs := attributes(span)
+	assert.Equal(t, "127.0.0.1", attrs[ServerAddressKey].AsString())
+	assert.Equal(t, int64(port), attrs[ServerPortKey].AsInt64())
+	assert.Equal(t, routeros.MethodGet, attrs[HTTPMethodKey].AsString())
+	assert.Equal(t, "print", attrs[VerbKey].AsString())
+	assert.Equal(t, "ip/address", attrs[CommandKey].AsString())
+	assert.Equal(t, int64(http.StatusOK), attrs[HTTPStatusKey].AsInt64())
+	assert.NotContains(t, attrs, ErrorMessageKey)
+}
+
+// TestMiddleware_APIError tests the span of a request failing with a RouterOS error
+func TestMiddleware_APIError(t *testing.T) {
+	provider, exporter := setupTracing(t)
+	client, _ := newTracedClient(t, provider, http.StatusBadRequest,
+		`{"error":400,"message":"Bad Request","detail":"failure: already have such address"}`)
+
+	_, err := client.Add(context.Background(), "ip/address", []byte(`{"address":"10.0.0.1/24"}`))
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "routeros add", span.Name)
+	assert.Equal(t, codes.Error, span.Status.Code)
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, "exception", span.Events[0].Name)
+
+	attrs := attributes(span)
+	assert.Equal(t, int64(http.StatusBadRequest), attrs[HTTPStatusKey].AsInt64())
+	assert.Equal(t, "failure: already have such address", attrs[ErrorMessageKey].AsString())
+}
+
+// TestMiddleware_Verbs tests the span names of the verbs of the client
+func TestMiddleware_Verbs(t *testing.T) {
+	provider, exporter := setupTracing(t)
+	client, _ := newTracedClient(t, provider, http.StatusOK, `{}`)
+	ctx := context.Background()
+
+	_, _ = client.Set(ctx, "ip/address/*1", []byte(`{}`))
+	_, _ = client.Remove(ctx, "ip/address/*1")
+	_, _ = client.Run(ctx, "system/reboot", nil)
+	_, _ = routeros.PrintAs[map[string]string](ctx, client, "system/resource")
+
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+	}
+	assert.Equal(t, []string{"routeros set", "routeros remove", "routeros run", "routeros print"}, names)
+}
+
+// TestMiddleware_PropagatesContext tests that the span is a child of the caller span and is passed to the inner middlewares
+func TestMiddleware_PropagatesContext(t *testing.T) {
+	provider, exporter := setupTracing(t)
+
+	var inner trace.SpanContext
+	client, _ := newTracedClient(t, provider, http.StatusOK, `[]`,
+		routeros.WithMiddleware(func(next routeros.Doer) routeros.Doer {
+			return routeros.DoerFunc(func(ctx context.Context, request *routeros.Request) (*routeros.Response, error) {
+				inner = trace.SpanContextFromContext(ctx)
+				return next.Do(ctx, request)
+			})
+		}))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "provision")
+	_, err := client.Print(ctx, "interface")
+	require.NoError(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	child := spans[0]
+	assert.Equal(t, parent.SpanContext().TraceID(), child.SpanContext.TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), child.Parent.SpanID())
+	assert.Equal(t, child.SpanContext.SpanID(), inner.SpanID())
+}
+
+// TestMiddleware_TransportError tests the span of a request that could not be sent
+func TestMiddleware_TransportError(t *testing.T) {
+	provider, exporter := setupTracing(t)
+	client, server := newTracedClient(t, provider, http.StatusOK, `[]`)
+	server.Close()
+
+	_, err := client.Print(context.Background(), "ip/address")
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.NotContains(t, attributes(spans[0]), HTTPStatusKey)
+}
@@ -0,0 +1,162 @@
+package routerosv7_restfull_api
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of the transport built by the client
+const (
+	DefaultTLSSessionCacheSize = 64               // DefaultTLSSessionCacheSize is the number of TLS sessions kept for resumption
+	defaultDialTimeout         = 30 * time.Second // defaultDialTimeout is the timeout for establishing a TCP connection
+	defaultKeepAlive           = 30 * time.Second // defaultKeepAlive is the interval between TCP keep-alive probes
+)
+
+// transportOptions holds the connection pooling settings collected from the transport options
+type transportOptions struct {
+	maxIdleConns        int           // Maximum number of idle connections across all hosts
+	maxIdleConnsPerHost int           // Maximum number of idle connections per host
+	maxConnsPerHost     int           // Maximum number of connections per host
+	idleConnTimeout     time.Duration // Time after which an idle connection is closed
+	keepAlive           time.Duration // Interval between TCP keep-alive probes, negative to disable them
+	disableKeepAlives   bool          // Whether every request uses a new connection
+	tlsSessionCacheSize int           // Number of TLS sessions kept for resumption, negative to disable the cache
+}
+
+// WithMaxIdleConns sets the maximum number of idle connections kept across all hosts, zero means no limit
+func WithMaxIdleConns(n int) Option {
+	return func(o *clientOptions) error {
+		// Check if the number is negative
+		if n < 0 {
+			return errors.New("WithMaxIdleConns: number must not be negative")
+		}
+		o.pool.maxIdleConns = n // Set the number of idle connections
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle connections kept per host, 2 by default
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *clientOptions) error {
+		// Check if the number is positive
+		if n <= 0 {
+			return errors.New("WithMaxIdleConnsPerHost: number must be positive")
+		}
+		o.pool.maxIdleConnsPerHost = n // Set the number of idle connections per host
+		return nil
+	}
+}
+
+// WithMaxConnsPerHost limits the number of connections per host, including those in use, zero means no limit
+func WithMaxConnsPerHost(n int) Option {
+	return func(o *clientOptions) error {
+		// Check if the number is negative
+		if n < 0 {
+			return errors.New("WithMaxConnsPerHost: number must not be negative")
+		}
+		o.pool.maxConnsPerHost = n // Set the number of connections per host
+		return nil
+	}
+}
+
+// WithIdleConnTimeout sets the time after which an idle connection is closed, 90 seconds by default
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) error {
+		// Check if the timeout is positive
+		if timeout <= 0 {
+			return errors.New("WithIdleConnTimeout: timeout must be positive")
+		}
+		o.pool.idleConnTimeout = timeout // Set the idle timeout
+		return nil
+	}
+}
+
+// WithKeepAlive sets the interval between TCP keep-alive probes, 30 seconds by default, negative to disable them
+func WithKeepAlive(interval time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.pool.keepAlive = interval // Set the keep-alive interval
+		return nil
+	}
+}
+
+// WithDisableKeepAlives disables HTTP keep-alives, so that every request uses a new connection
+func WithDisableKeepAlives() Option {
+	return func(o *clientOptions) error {
+		o.pool.disableKeepAlives = true // Disable the keep-alives
+		return nil
+	}
+}
+
+/*
+WithTLSSessionCache sets the number of TLS sessions kept for resumption, DefaultTLSSessionCacheSize by default.
+Resumed sessions skip the certificate exchange when a new connection is needed. Zero disables the cache.
+*/
+func WithTLSSessionCache(size int) Option {
+	return func(o *clientOptions) error {
+		// Check if the size is negative
+		if size < 0 {
+			return errors.New("WithTLSSessionCache: size must not be negative")
+		}
+		o.pool.tlsSessionCacheSize = size // Set the size
+		if size == 0 {
+			o.pool.tlsSessionCacheSize = -1 // Disable the cache
+		}
+		return nil
+	}
+}
+
+// applyTransportOptions applies the connection pooling settings to the transport
+func applyTransportOptions(transport *http.Transport, options transportOptions) {
+
+	// Dial with the keep-alive interval
+	keepAlive := options.keepAlive
+	if keepAlive == 0 {
+		keepAlive = defaultKeepAlive
+	}
+	transport.DialContext = (&net.Dialer{Timeout: defaultDialTimeout, KeepAlive: keepAlive}).DialContext
+
+	// Set the pool limits
+	if options.maxIdleConns != 0 {
+		transport.MaxIdleConns = options.maxIdleConns
+	}
+	if options.maxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = options.maxIdleConnsPerHost
+	}
+	if options.maxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = options.maxConnsPerHost
+	}
+	if options.idleConnTimeout != 0 {
+		transport.IdleConnTimeout = options.idleConnTimeout
+	}
+	transport.DisableKeepAlives = options.disableKeepAlives
+
+	// Keep TLS sessions for resumption unless the TLS configuration already has a cache
+	if options.tlsSessionCacheSize >= 0 && transport.TLSClientConfig.ClientSessionCache == nil {
+		size := options.tlsSessionCacheSize
+		if size == 0 {
+			size = DefaultTLSSessionCacheSize
+		}
+		transport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(size)
+	}
+}
+
+// Transport shared by the package-level functions
+var (
+	sharedTransportOnce sync.Once       // sharedTransportOnce creates the shared transport once
+	sharedTransport     *http.Transport // sharedTransport is the transport shared by the package-level functions
+)
+
+/*
+packageTransport returns the transport shared by the package-level functions such as Print, so that their
+connections and TLS sessions are reused between calls
+*/
+func packageTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = newTransport(clientOptions{}, Endpoint{}).(*http.Transport)
+	})
+	return sharedTransport
+}
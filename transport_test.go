@@ -0,0 +1,201 @@
+package routerosv7_restfull_api
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshakeCounter counts the TLS handshakes completed by a test server
+type handshakeCounter struct {
+	full    atomic.Int64 // Number of full handshakes
+	resumed atomic.Int64 // Number of resumed handshakes
+}
+
+// setupHandshakeCountingServer creates a TLS server answering an empty list and counting its handshakes
+func setupHandshakeCountingServer(tb testing.TB) (*httptest.Server, *handshakeCounter) {
+	counter := &handshakeCounter{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	server.TLS = &tls.Config{
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if state.DidResume {
+				counter.resumed.Add(1)
+			} else {
+				counter.full.Add(1)
+			}
+			return nil
+		},
+	}
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+	return server, counter
+}
+
+// TestTransportOptions tests that the transport options are applied to the transport built by the client
+func TestTransportOptions(t *testing.T) {
+	client, err := NewClient("router.lan",
+		WithMaxIdleConns(200),
+		WithMaxIdleConnsPerHost(8),
+		WithMaxConnsPerHost(16),
+		WithIdleConnTimeout(time.Minute),
+		WithKeepAlive(15*time.Second),
+		WithDisableKeepAlives(),
+	)
+	require.NoError(t, err)
+
+	transport := client.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, 200, transport.MaxIdleConns)
+	assert.Equal(t, 8, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 16, transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.True(t, transport.DisableKeepAlives)
+	assert.NotNil(t, transport.DialContext)
+	assert.NotNil(t, transport.TLSClientConfig.ClientSessionCache)
+
+	// The TLS session cache can be disabled
+	client, err = NewClient("router.lan", WithTLSSessionCache(0))
+	require.NoError(t, err)
+	assert.Nil(t, client.httpClient.Transport.(*http.Transport).TLSClientConfig.ClientSessionCache)
+
+	// The session cache of the TLS configuration is kept
+	cache := tls.NewLRUClientSessionCache(1)
+	client, err = NewClient("router.lan", WithTLSConfig(&tls.Config{ClientSessionCache: cache}))
+	require.NoError(t, err)
+	assert.Equal(t, cache, client.httpClient.Transport.(*http.Transport).TLSClientConfig.ClientSessionCache)
+}
+
+// TestTransportOptions_Invalid tests that invalid transport options are rejected by NewClient
+func TestTransportOptions_Invalid(t *testing.T) {
+	for _, option := range []Option{
+		WithMaxIdleConns(-1),
+		WithMaxIdleConnsPerHost(0),
+		WithMaxConnsPerHost(-1),
+		WithIdleConnTimeout(0),
+		WithTLSSessionCache(-1),
+	} {
+		_, err := NewClient("router.lan", option)
+		assert.Error(t, err)
+	}
+}
+
+// TestClient_ReusesTLSConnection tests that a client performs a single handshake for many requests
+func TestClient_ReusesTLSConnection(t *testing.T) {
+	server, counter := setupHandshakeCountingServer(t)
+
+	client, err := NewClient(server.URL, WithRootCAsPEM(serverCertificatePEM(server)))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := client.Print(context.Background(), "ip/address")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(1), counter.full.Load())
+	assert.Equal(t, int64(0), counter.resumed.Load())
+}
+
+// TestClient_ResumesTLSSession tests that new connections resume the TLS session instead of a full handshake
+func TestClient_ResumesTLSSession(t *testing.T) {
+	server, counter := setupHandshakeCountingServer(t)
+
+	client, err := NewClient(server.URL, WithRootCAsPEM(serverCertificatePEM(server)), WithDisableKeepAlives())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Print(context.Background(), "ip/address")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(1), counter.full.Load())
+	assert.Equal(t, int64(2), counter.resumed.Load())
+}
+
+// TestPackageFunctions_ShareConnections tests that the package-level functions reuse connections between calls
+func TestPackageFunctions_ShareConnections(t *testing.T) {
+	var connections atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err := Print(context.Background(), serverHost(server), "admin", "secret", "ip/address")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(1), connections.Load())
+}
+
+// benchmarkPrint runs Print against a TLS server with the client returned by newClient for every iteration
+func benchmarkPrint(b *testing.B, newClient func(server *httptest.Server) *Client) {
+	server, counter := setupHandshakeCountingServer(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := newClient(server).Print(context.Background(), "ip/address"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(counter.full.Load())/float64(b.N), "full-handshakes/op")
+	b.ReportMetric(float64(counter.resumed.Load())/float64(b.N), "resumed-handshakes/op")
+}
+
+// newBenchmarkClient creates a client trusting the certificate of the server
+func newBenchmarkClient(b *testing.B, server *httptest.Server, opts ...Option) *Client {
+	client, err := NewClient(server.URL, append(opts, WithRootCAsPEM(serverCertificatePEM(server)))...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return client
+}
+
+// BenchmarkPrint_NewClientPerRequest creates a new client, and therefore a new transport, for every request
+func BenchmarkPrint_NewClientPerRequest(b *testing.B) {
+	benchmarkPrint(b, func(server *httptest.Server) *Client {
+		client := newBenchmarkClient(b, server)
+		b.Cleanup(client.httpClient.CloseIdleConnections)
+		return client
+	})
+}
+
+// BenchmarkPrint_SharedClient reuses one client, and therefore its pooled connection, for every request
+func BenchmarkPrint_SharedClient(b *testing.B) {
+	var client *Client
+	benchmarkPrint(b, func(server *httptest.Server) *Client {
+		if client == nil {
+			client = newBenchmarkClient(b, server)
+		}
+		return client
+	})
+}
+
+// BenchmarkPrint_SessionResumption reuses one client without keep-alives, so every request resumes the TLS session
+func BenchmarkPrint_SessionResumption(b *testing.B) {
+	var client *Client
+	benchmarkPrint(b, func(server *httptest.Server) *Client {
+		if client == nil {
+			client = newBenchmarkClient(b, server, WithDisableKeepAlives())
+		}
+		return client
+	})
+}